@@ -0,0 +1,103 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"io"
+)
+
+// CloseOnDying arranges for c to be closed as soon as t starts dying.
+// Resources are closed in the reverse order of registration, and if
+// t is already dying, c is closed immediately. An error returned by
+// Close, or a panic while closing, is recorded as the reason for the
+// goroutine death if no other error was recorded yet, and as a
+// suppressed error in the death report otherwise. A failing resource
+// doesn't prevent the remaining ones from being closed. Done waits for
+// these resources to be closed, even when that happens in the goroutine
+// that called Kill, and the reason of a dead tomb is never changed.
+//
+// The returned function deregisters c, if it wasn't closed yet.
+func (t *Tomb) CloseOnDying(c io.Closer) (remove func()) {
+	return t.onDying(func(error) {
+		if err := c.Close(); err != nil {
+			t.fold(err)
+		}
+	})
+}
+
+// CloseOnDead arranges for c to be closed when Done is called, right
+// before t is flagged as dead. Resources are closed in the reverse order
+// of registration, and always after all resources registered via
+// CloseOnDying. Errors and panics are handled as with CloseOnDying.
+// If Done is already closing resources, c is closed immediately, and
+// if t is already dead, c is closed immediately and any error is
+// dropped.
+//
+// The returned function deregisters c, if it wasn't closed yet.
+func (t *Tomb) CloseOnDead(c io.Closer) (remove func()) {
+	t.init()
+	t.m.Lock()
+	if !t.closingDead {
+		dc := &deadCloser{c}
+		x := t.extra()
+		x.deadClosers = append(x.deadClosers, dc)
+		t.m.Unlock()
+		return func() { t.removeDeadCloser(dc) }
+	}
+	dead := t.deadAt != 0
+	t.m.Unlock()
+	if dead {
+		c.Close()
+	} else {
+		t.safely(func() {
+			if err := c.Close(); err != nil {
+				t.fold(err)
+			}
+		})
+	}
+	return func() {}
+}
+
+type deadCloser struct {
+	c io.Closer
+}
+
+func (t *Tomb) removeDeadCloser(dc *deadCloser) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x == nil {
+		return
+	}
+	for i, other := range t.x.deadClosers {
+		if other == dc {
+			t.x.deadClosers = append(t.x.deadClosers[:i], t.x.deadClosers[i+1:]...)
+			return
+		}
+	}
+}
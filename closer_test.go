@@ -0,0 +1,182 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type closer struct {
+	name   string
+	err    error
+	closed *[]string
+}
+
+func (c closer) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestCloseOnDying(t *testing.T) {
+	var closed []string
+	tb := &tomb.Tomb{}
+	err := errors.New("close error")
+	tb.CloseOnDying(closer{"a", nil, &closed})
+	tb.CloseOnDying(closer{"b", err, &closed})
+	tb.CloseOnDead(closer{"c", nil, &closed})
+	if len(closed) != 0 {
+		t.Fatalf("closed while alive: %v", closed)
+	}
+
+	tb.Kill(nil)
+	if len(closed) != 2 || closed[0] != "b" || closed[1] != "a" {
+		t.Fatalf("CloseOnDying: wrong closing order: %v", closed)
+	}
+	testState(t, tb, true, false, err)
+	if !tb.Killed() {
+		t.Fatalf("Killed: want true")
+	}
+
+	tb.Done()
+	if len(closed) != 3 || closed[2] != "c" {
+		t.Fatalf("CloseOnDead: not closed on Done: %v", closed)
+	}
+	testState(t, tb, true, true, err)
+
+	// resources registered after the fact are closed at once
+	tb.CloseOnDying(closer{"d", nil, &closed})
+	tb.CloseOnDead(closer{"e", nil, &closed})
+	if len(closed) != 5 || closed[3] != "d" || closed[4] != "e" {
+		t.Fatalf("late registration: got %v", closed)
+	}
+}
+
+func TestCloseOnDeadError(t *testing.T) {
+	var closed []string
+	tb := &tomb.Tomb{}
+	err := errors.New("close error")
+	tb.CloseOnDead(closer{"a", err, &closed})
+	tb.Done()
+	testState(t, tb, true, true, err)
+	if tb.Killed() {
+		t.Fatalf("Killed: want false")
+	}
+}
+
+type panicCloser struct{}
+
+func (panicCloser) Close() error {
+	panic("BOOM")
+}
+
+func TestClosePanic(t *testing.T) {
+	var closed []string
+	tb := &tomb.Tomb{}
+	err := errors.New("some error")
+	tb.CloseOnDying(closer{"a", nil, &closed})
+	tb.CloseOnDying(panicCloser{})
+	tb.CloseOnDead(closer{"b", nil, &closed})
+	tb.CloseOnDead(panicCloser{})
+	tb.Kill(err)
+	tb.Done()
+	if len(closed) != 2 {
+		t.Fatalf("panicking closer prevented others from closing: %v", closed)
+	}
+	testState(t, tb, true, true, err)
+	r := tb.Report()
+	if len(r.Suppressed) != 2 || r.Suppressed[0].Error() != "tomb: panic during shutdown: BOOM" {
+		t.Fatalf("Report: want panics as suppressed errors, got %v", r.Suppressed)
+	}
+}
+
+func TestCloseRemove(t *testing.T) {
+	var closed []string
+	tb := &tomb.Tomb{}
+	tb.CloseOnDying(closer{"a", nil, &closed})
+	remove := tb.CloseOnDying(closer{"b", nil, &closed})
+	tb.CloseOnDying(closer{"c", nil, &closed})
+	tb.CloseOnDead(closer{"d", nil, &closed})
+	removeDead := tb.CloseOnDead(closer{"e", nil, &closed})
+	tb.CloseOnDead(closer{"f", nil, &closed})
+	remove()
+	removeDead()
+	tb.Done()
+	if strings.Join(closed, "") != "cafd" {
+		t.Fatalf("want closing order cafd, got %v", closed)
+	}
+	// removing after closing is harmless
+	remove()
+	removeDead()
+}
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error {
+	return f()
+}
+
+func TestCloseOnDeadDuringDone(t *testing.T) {
+	// resources registered while Done is closing are closed too
+	var closed []string
+	tb := &tomb.Tomb{}
+	err := errors.New("close error")
+	tb.CloseOnDying(closeFunc(func() error {
+		tb.CloseOnDead(closer{"late", err, &closed})
+		return nil
+	}))
+	tb.Done()
+	if len(closed) != 1 || closed[0] != "late" {
+		t.Fatalf("CloseOnDead: late resource not closed: %v", closed)
+	}
+	testState(t, tb, true, true, err)
+}
+
+func TestCloseOnDyingSlow(t *testing.T) {
+	// Done waits for resources being closed by the killing goroutine,
+	// and closes the CloseOnDead ones only afterwards.
+	var mu sync.Mutex
+	var closed []string
+	tb := &tomb.Tomb{}
+	err := errors.New("close failed")
+	tb.CloseOnDead(closeFunc(func() error {
+		mu.Lock()
+		closed = append(closed, "dead")
+		mu.Unlock()
+		return nil
+	}))
+	tb.CloseOnDying(closeFunc(func() error {
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		closed = append(closed, "dying")
+		mu.Unlock()
+		return err
+	}))
+	go tb.Kill(nil)
+	<-tb.Dying()
+	tb.Done()
+	if werr := tb.Wait(); werr != err {
+		t.Fatalf("Wait: want close error, got %v", werr)
+	}
+	if len(closed) != 2 || closed[0] != "dying" || closed[1] != "dead" {
+		t.Fatalf("Done: wrong closing order: %v", closed)
+	}
+}
+
+func TestCloseOnDyingAfterDeath(t *testing.T) {
+	// errors from resources closed after death don't change the reason
+	var closed []string
+	tb := &tomb.Tomb{}
+	tb.Done()
+	err := errors.New("close error")
+	tb.CloseOnDying(closer{"a", err, &closed})
+	if len(closed) != 1 {
+		t.Fatalf("CloseOnDying: not closed on a dead tomb")
+	}
+	testState(t, tb, true, true, nil)
+	if r := tb.Report(); len(r.Suppressed) != 1 || r.Suppressed[0] != err {
+		t.Fatalf("Report: want close error suppressed, got %v", r.Suppressed)
+	}
+}
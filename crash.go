@@ -0,0 +1,106 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+)
+
+// WithCrashDump makes the tomb write a crash dump to w when it dies
+// with an error. The dump holds the death report followed by the stack
+// traces of all goroutines, and is written by Done right before the
+// tomb is flagged as dead. Clean deaths with no error write nothing.
+func WithCrashDump(w io.Writer) Option {
+	return func(t *Tomb) {
+		t.m.Lock()
+		t.extra().crashDump = w
+		t.m.Unlock()
+	}
+}
+
+// A DeathProfile holds the profiles captured when a tomb created with
+// WithDeathProfile dies with an error.
+type DeathProfile struct {
+	Report *DeathReport
+
+	// Goroutine and Heap hold the goroutine and heap profiles, in
+	// the gzipped protobuf format read by the pprof tool. Heap is
+	// only captured if requested.
+	Goroutine []byte
+	Heap      []byte
+}
+
+// WithDeathProfile makes the tomb capture the goroutine profile, and
+// the heap profile as well if heap is true, when it dies with an error,
+// and deliver them to hook for storage or upload. The profiles are
+// captured by Done right before the tomb is flagged as dead, and hook
+// is called from that same goroutine. Clean deaths capture nothing.
+func WithDeathProfile(heap bool, hook func(p *DeathProfile)) Option {
+	return func(t *Tomb) {
+		t.m.Lock()
+		x := t.extra()
+		x.profileHook = hook
+		x.profileHeap = heap
+		t.m.Unlock()
+	}
+}
+
+func captureProfile(report *DeathReport, heap bool) *DeathProfile {
+	p := &DeathProfile{Report: report, Goroutine: writeProfile("goroutine")}
+	if heap {
+		p.Heap = writeProfile("heap")
+	}
+	return p
+}
+
+func writeProfile(name string) []byte {
+	var buf bytes.Buffer
+	pprof.Lookup(name).WriteTo(&buf, 0)
+	return buf.Bytes()
+}
+
+func writeCrashDump(w io.Writer, report *DeathReport) {
+	fmt.Fprintf(w, "%s\n\n%s\n", report, allStacks())
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
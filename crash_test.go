@@ -0,0 +1,59 @@
+package tomb_test
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/tomb.v1"
+	"strings"
+	"testing"
+)
+
+func TestCrashDump(t *testing.T) {
+	var buf bytes.Buffer
+	tb := tomb.New(tomb.WithCrashDump(&buf))
+	tb.Killf("BOOM")
+	tb.Done()
+	dump := buf.String()
+	if !strings.HasPrefix(dump, fmt.Sprintf("tomb %d died: BOOM\n", tb.ID())) || !strings.Contains(dump, "goroutine ") ||
+		!strings.Contains(dump, "TestCrashDump") {
+		t.Fatalf("bad crash dump:\n%s", dump)
+	}
+
+	// clean deaths don't dump anything
+	buf.Reset()
+	tb = tomb.New(tomb.WithCrashDump(&buf))
+	tb.Kill(nil)
+	tb.Done()
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected crash dump:\n%s", buf.String())
+	}
+}
+
+func TestDeathProfile(t *testing.T) {
+	var got *tomb.DeathProfile
+	tb := tomb.New(tomb.WithDeathProfile(true, func(p *tomb.DeathProfile) { got = p }))
+	tb.Killf("BOOM")
+	tb.Done()
+	if got == nil || got.Report.Reason != tb.Err() {
+		t.Fatalf("WithDeathProfile: bad profile %#v", got)
+	}
+	gzipped := []byte{0x1f, 0x8b}
+	if !bytes.HasPrefix(got.Goroutine, gzipped) || !bytes.HasPrefix(got.Heap, gzipped) {
+		t.Fatalf("WithDeathProfile: profiles not in pprof format")
+	}
+
+	// the heap profile is optional, and clean deaths capture nothing
+	got = nil
+	tb = tomb.New(tomb.WithDeathProfile(false, func(p *tomb.DeathProfile) { got = p }))
+	tb.Kill(nil)
+	tb.Done()
+	if got != nil {
+		t.Fatalf("WithDeathProfile: unexpected profile on clean death")
+	}
+	tb = tomb.New(tomb.WithDeathProfile(false, func(p *tomb.DeathProfile) { got = p }))
+	tb.Killf("BOOM")
+	tb.Done()
+	if got == nil || len(got.Goroutine) == 0 || got.Heap != nil {
+		t.Fatalf("WithDeathProfile: want goroutine profile only, got %#v", got)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	defaultOnce sync.Once
+	defaultTomb *Tomb
+)
+
+// Default returns the process-wide default tomb, creating it on first
+// use. The default tomb starts dying with no error when the process
+// receives SIGINT or SIGTERM, which gives small programs a graceful
+// shutdown on those signals with no setup:
+//
+//	func main() {
+//		go func() {
+//			defer tomb.Done()
+//			// ... work until <-tomb.Default().Dying() ...
+//		}()
+//		if err := tomb.Wait(); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func Default() *Tomb {
+	defaultOnce.Do(func() {
+		t := New(WithName("default"))
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		gen, dying := t.incarnation(), t.Dying()
+		go func() {
+			select {
+			case <-sigs:
+				t.killIncarnation(gen, nil, OriginTrigger)
+			case <-dying:
+			}
+			signal.Stop(sigs)
+		}()
+		defaultTomb = t
+	})
+	return defaultTomb
+}
+
+// Kill calls Kill on the default tomb.
+func Kill(reason error) {
+	Default().killFrom(reason, OriginKill, 2)
+}
+
+// Done calls Done on the default tomb.
+func Done() {
+	Default().Done()
+}
+
+// Wait calls Wait on the default tomb.
+func Wait() error {
+	return Default().Wait()
+}
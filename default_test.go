@@ -0,0 +1,51 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send SIGINT to self on windows")
+	}
+	// The default tomb lives for the whole process and stops watching
+	// signals after the first one, so the test runs in a new process
+	// to keep it repeatable.
+	if os.Getenv("TOMB_TEST_DEFAULT") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestDefault$", "-test.count=1")
+		cmd.Env = append(os.Environ(), "TOMB_TEST_DEFAULT=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("TestDefault subprocess failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	tb := tomb.Default()
+	if tomb.Default() != tb || tb.Name() != "default" {
+		t.Fatalf("Default: want the same tomb on every call")
+	}
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	<-tb.Dying()
+	testState(t, tb, true, false, nil)
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginTrigger {
+		t.Fatalf("DeathOrigin: want trigger, got %v", o)
+	}
+
+	tomb.Kill(nil)
+	tomb.Done()
+	if err := tomb.Wait(); err != nil {
+		t.Fatalf("Wait: want nil, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// debugEvents enables event tracing on every tomb, with
+// debugEventsSize entries, when TOMB_DEBUG is set.
+var debugEvents = os.Getenv("TOMB_DEBUG") != ""
+
+const debugEventsSize = 64
+
+// EventKind identifies a lifecycle event recorded by a tomb.
+type EventKind int
+
+const (
+	EventKill EventKind = iota + 1
+	EventDying
+	EventDead
+	EventReady
+	EventPause
+	EventResume
+	EventProgress
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventKill:
+		return "kill"
+	case EventDying:
+		return "dying"
+	case EventDead:
+		return "dead"
+	case EventReady:
+		return "ready"
+	case EventPause:
+		return "pause"
+	case EventResume:
+		return "resume"
+	case EventProgress:
+		return "progress"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// An Event is a lifecycle event recorded by a tomb with event
+// tracing enabled.
+type Event struct {
+	Time time.Time
+	Kind EventKind
+
+	// Err holds the reason provided to Kill for EventKill events,
+	// and the reason for the goroutine death for EventDying and
+	// EventDead events.
+	Err error
+
+	// Caller holds the file and line of the call that caused
+	// EventKill events, when known.
+	Caller string
+
+	// Done and Total hold the progress reported for EventProgress
+	// events.
+	Done  int
+	Total int
+
+	pc uintptr
+}
+
+func (e Event) String() string {
+	s := e.Time.Format(time.RFC3339Nano) + " " + e.Kind.String()
+	if e.Kind == EventKill || e.Kind == EventDying || e.Kind == EventDead {
+		s += fmt.Sprintf(": %v", e.Err)
+	}
+	if e.Kind == EventProgress {
+		s += fmt.Sprintf(": %d/%d", e.Done, e.Total)
+	}
+	if e.Caller != "" {
+		s += " at " + e.Caller
+	}
+	return s
+}
+
+// eventRing holds the most recent events recorded by a tomb.
+type eventRing struct {
+	buf []Event
+	n   int // Total number of events recorded.
+}
+
+// WithEventTrace makes the tomb keep the last size lifecycle events,
+// retrievable via LastEvents. Event tracing is also enabled on all
+// tombs, with a default size, when the TOMB_DEBUG environment variable
+// is set.
+func WithEventTrace(size int) Option {
+	return func(t *Tomb) {
+		t.m.Lock()
+		defer t.m.Unlock()
+		if size > 0 {
+			t.extra().events = &eventRing{buf: make([]Event, size)}
+		} else if t.x != nil {
+			t.x.events = nil
+		}
+	}
+}
+
+// tracing returns whether event tracing is enabled for t.
+// It must be called with t.m held.
+func (t *Tomb) tracing() bool {
+	if t.x == nil || t.x.events == nil {
+		if !debugEvents {
+			return false
+		}
+		t.extra().events = &eventRing{buf: make([]Event, debugEventsSize)}
+	}
+	return true
+}
+
+// record records an event of the given kind if event tracing
+// is enabled. It must be called with t.m held.
+func (t *Tomb) record(kind EventKind, err error) {
+	if t.tracing() {
+		t.recordAt(time.Now(), kind, err, 0)
+	}
+}
+
+// recordAt records an event that happened at the provided time,
+// caused by the call at pc if not zero. Event tracing must be enabled.
+// It must be called with t.m held.
+func (t *Tomb) recordAt(now time.Time, kind EventKind, err error, pc uintptr) {
+	r := t.x.events
+	r.buf[r.n%len(r.buf)] = Event{Time: now, Kind: kind, Err: err, pc: pc}
+	r.n++
+}
+
+// LastEvents returns up to n of the most recent lifecycle events
+// recorded by t, oldest first, or all the recorded events if n is not
+// positive. Events are only recorded if tracing was enabled via the
+// WithEventTrace option or the TOMB_DEBUG environment variable.
+func (t *Tomb) LastEvents(n int) []Event {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x == nil || t.x.events == nil {
+		return nil
+	}
+	r := t.x.events
+	avail := r.n
+	if avail > len(r.buf) {
+		avail = len(r.buf)
+	}
+	if n <= 0 || n > avail {
+		n = avail
+	}
+	events := make([]Event, n)
+	for i := range events {
+		e := r.buf[(r.n-n+i)%len(r.buf)]
+		if e.pc != 0 {
+			e.Caller = pcLocation(e.pc)
+		}
+		events[i] = e
+	}
+	return events
+}
@@ -0,0 +1,47 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"strings"
+	"testing"
+)
+
+func TestLastEvents(t *testing.T) {
+	if events := (&tomb.Tomb{}).LastEvents(0); events != nil {
+		t.Fatalf("LastEvents: want nothing without tracing, got %v", events)
+	}
+
+	tb := tomb.New(tomb.WithEventTrace(4))
+	tb.MarkReady()
+	tb.Pause()
+	tb.Resume()
+	err := errors.New("some error")
+	tb.Kill(err)
+	tb.Done()
+
+	want := []tomb.EventKind{tomb.EventResume, tomb.EventKill, tomb.EventDying, tomb.EventDead}
+	events := tb.LastEvents(0)
+	if len(events) != len(want) {
+		t.Fatalf("LastEvents: want %d events, got %v", len(want), events)
+	}
+	for i, e := range events {
+		if e.Kind != want[i] {
+			t.Fatalf("LastEvents: want %v, got %v", want, events)
+		}
+		if i > 0 && e.Time.Before(events[i-1].Time) {
+			t.Fatalf("LastEvents: events out of order: %v", events)
+		}
+	}
+	if events[1].Err != err || events[3].Err != err {
+		t.Fatalf("LastEvents: wrong errors: %v", events)
+	}
+	if !strings.Contains(events[1].Caller, "events_test.go:") || events[2].Caller != "" {
+		t.Fatalf("LastEvents: wrong callers: %v", events)
+	}
+
+	events = tb.LastEvents(2)
+	if len(events) != 2 || events[0].Kind != tomb.EventDying || events[1].Kind != tomb.EventDead {
+		t.Fatalf("LastEvents(2): got %v", events)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A Group manages a set of sibling tombs as a unit.
+//
+// The zero value of a Group is ready to use.
+type Group struct {
+	m     sync.Mutex
+	names []string
+	tombs map[string]*Tomb
+}
+
+// Add registers t in the group under the given name. It's a runtime
+// error to register the same name twice.
+func (g *Group) Add(name string, t *Tomb) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if _, ok := g.tombs[name]; ok {
+		panic(fmt.Sprintf("tomb: group already has %q", name))
+	}
+	if g.tombs == nil {
+		g.tombs = make(map[string]*Tomb)
+	}
+	g.names = append(g.names, name)
+	g.tombs[name] = t
+}
+
+// Tomb returns the tomb registered under name, or nil if there's none.
+func (g *Group) Tomb(name string) *Tomb {
+	g.m.Lock()
+	defer g.m.Unlock()
+	return g.tombs[name]
+}
+
+func (g *Group) list() (names []string, tombs []*Tomb) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	names = append(names, g.names...)
+	for _, name := range names {
+		tombs = append(tombs, g.tombs[name])
+	}
+	return names, tombs
+}
+
+// KillAll calls Kill with the given reason on every tomb in the group
+// that is not dead yet. Dead tombs keep the reason they died with.
+func (g *Group) KillAll(reason error) {
+	_, tombs := g.list()
+	for _, t := range tombs {
+		if t.State() != Dead {
+			t.Kill(reason)
+		}
+	}
+}
+
+// WaitAll blocks until every tomb in the group is dead. The returned
+// error joins the reasons for the death of every tomb that died with
+// an error, prefixed by the tomb name.
+func (g *Group) WaitAll() error {
+	names, tombs := g.list()
+	var errs []error
+	for i, t := range tombs {
+		if err := t.Wait(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FirstDead blocks until any tomb in the group is dead, and returns
+// it with the name it was registered under. If several tombs are
+// already dead, the one that died first is returned. If the group
+// is empty, FirstDead returns immediately with a nil tomb.
+func (g *Group) FirstDead() (name string, t *Tomb) {
+	names, tombs := g.list()
+	if len(tombs) == 0 {
+		return "", nil
+	}
+	first := -1
+	for i, t := range tombs {
+		deadAt := t.DeadAt()
+		if !deadAt.IsZero() && (first < 0 || deadAt.Before(tombs[first].DeadAt())) {
+			first = i
+		}
+	}
+	if first < 0 {
+		cases := make([]reflect.SelectCase, len(tombs))
+		for i, t := range tombs {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(t.Dead())}
+		}
+		first, _, _ = reflect.Select(cases)
+	}
+	return names[first], tombs[first]
+}
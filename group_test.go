@@ -0,0 +1,58 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	var g tomb.Group
+	a, b, c := &tomb.Tomb{}, &tomb.Tomb{}, &tomb.Tomb{}
+	g.Add("a", a)
+	g.Add("b", b)
+	g.Add("c", c)
+	if g.Tomb("b") != b || g.Tomb("d") != nil {
+		t.Fatalf("Tomb: wrong lookup result")
+	}
+
+	b.Done()
+	if name, tb := g.FirstDead(); name != "b" || tb != b {
+		t.Fatalf("FirstDead: want b, got %q", name)
+	}
+
+	err := errors.New("some error")
+	g.KillAll(err)
+	testState(t, a, true, false, err)
+	testState(t, b, true, true, nil)
+	testState(t, c, true, false, err)
+
+	a.Done()
+	c.Done()
+	if name, _ := g.FirstDead(); name != "b" {
+		t.Fatalf("FirstDead: want b, got %q", name)
+	}
+	werr := g.WaitAll()
+	if !errors.Is(werr, err) || werr.Error() != "a: some error\nc: some error" {
+		t.Fatalf("WaitAll: got %v", werr)
+	}
+}
+
+func TestGroupFirstDeadBlocks(t *testing.T) {
+	var g tomb.Group
+	a, b := &tomb.Tomb{}, &tomb.Tomb{}
+	g.Add("a", a)
+	g.Add("b", b)
+	go a.Done()
+	if name, tb := g.FirstDead(); name != "a" || tb != a {
+		t.Fatalf("FirstDead: want a, got %q", name)
+	}
+
+	var empty tomb.Group
+	if name, tb := empty.FirstDead(); name != "" || tb != nil {
+		t.Fatalf("FirstDead: want nothing from an empty group")
+	}
+	if err := empty.WaitAll(); err != nil {
+		t.Fatalf("WaitAll: want nil, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+	"time"
+)
+
+// A TimeoutError is the reason for the death of a tomb killed by one
+// of its timers. It matches ErrTimeout via errors.Is.
+type TimeoutError struct {
+	Timer string // The timer that fired, such as "idle".
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("tomb: %s timeout after %v", e.Timer, e.After)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// Timeout returns true, as done by net.Error implementations.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// WithIdleKill makes the tomb kill itself with the provided reason if
+// no activity is seen for the duration d. Activity is reported by
+// calling Touch. The timer starts when the tomb is created and stops
+// once it starts dying. If reason is nil, a *TimeoutError matching
+// ErrTimeout is used instead.
+func WithIdleKill(d time.Duration, reason error) Option {
+	if reason == nil {
+		reason = &TimeoutError{Timer: "idle", After: d}
+	}
+	return func(t *Tomb) {
+		gen := t.incarnation()
+		timer := time.AfterFunc(d, func() {
+			t.killIncarnation(gen, reason, OriginTimeout)
+		})
+		t.m.Lock()
+		x := t.extra()
+		x.idleTimer = timer
+		x.idleTimeout = d
+		t.m.Unlock()
+		t.onDying(func(error) { timer.Stop() })
+	}
+}
+
+// Touch reports activity on t, postponing its death by WithIdleKill.
+// Touch has no effect on tombs created without that option.
+func (t *Tomb) Touch() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x != nil && t.x.idleTimer != nil && t.reason == ErrStillAlive {
+		t.x.idleTimer.Reset(t.x.idleTimeout)
+	}
+}
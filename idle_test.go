@@ -0,0 +1,40 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"testing"
+	"time"
+)
+
+func TestIdleKill(t *testing.T) {
+	err := errors.New("peer vanished")
+	tb := tomb.New(tomb.WithIdleKill(100*time.Millisecond, err))
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		tb.Touch()
+		time.Sleep(10 * time.Millisecond)
+	}
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	<-tb.Dying()
+	testState(t, tb, true, false, err)
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginTimeout {
+		t.Fatalf("DeathOrigin: want timeout, got %v", o)
+	}
+
+	// touching tombs without the option is harmless
+	(&tomb.Tomb{}).Touch()
+}
+
+func TestIdleKillTimeout(t *testing.T) {
+	tb := tomb.New(tomb.WithIdleKill(time.Millisecond, nil))
+	<-tb.Dying()
+	var terr *tomb.TimeoutError
+	if !errors.Is(tb.Err(), tomb.ErrTimeout) || !errors.As(tb.Err(), &terr) || terr.Timer != "idle" {
+		t.Fatalf("Err: want idle timeout, got %#v", tb.Err())
+	}
+	if s := tb.Err().Error(); s != "tomb: idle timeout after 1ms" {
+		t.Fatalf("Error: got %q", s)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"io"
+)
+
+// Reader returns a reader that reads from r until t starts dying,
+// after which reads fail with ErrDying. If r is also an io.Closer, it
+// is closed as soon as t starts dying, so that blocked reads return,
+// and the returned reader is an io.Closer as well. Closing it closes r
+// and stops watching t.
+func Reader(t *Tomb, r io.Reader) io.Reader {
+	tr := &reader{t, r}
+	if c, ok := r.(io.Closer); ok {
+		return &readCloser{tr, c, closeOnDying(t, r)}
+	}
+	return tr
+}
+
+// Writer returns a writer that writes to w until t starts dying,
+// after which writes fail with ErrDying. If w is also an io.Closer, it
+// is closed as soon as t starts dying, so that blocked writes return,
+// and the returned writer is an io.Closer as well. Closing it closes w
+// and stops watching t.
+func Writer(t *Tomb, w io.Writer) io.Writer {
+	tw := &writer{t, w}
+	if c, ok := w.(io.Closer); ok {
+		return &writeCloser{tw, c, closeOnDying(t, w)}
+	}
+	return tw
+}
+
+// Copy works like io.Copy, but stops with ErrDying as soon as t starts
+// dying. Either dst or src that is also an io.Closer is closed if t
+// starts dying while the copy is in progress, so that a blocked copy
+// is interrupted. Neither is closed once Copy returns.
+func Copy(t *Tomb, dst io.Writer, src io.Reader) (written int64, err error) {
+	defer closeOnDying(t, dst)()
+	defer closeOnDying(t, src)()
+	return io.Copy(&writer{t, dst}, &reader{t, src})
+}
+
+// closeOnDying closes v when t starts dying if v is an io.Closer.
+// The returned function stops watching t.
+func closeOnDying(t *Tomb, v interface{}) (remove func()) {
+	if c, ok := v.(io.Closer); ok {
+		return t.onDying(func(error) { c.Close() })
+	}
+	return func() {}
+}
+
+// dying returns whether t is dying, without blocking.
+func dying(t *Tomb) bool {
+	select {
+	case <-t.Dying():
+		return true
+	default:
+		return false
+	}
+}
+
+type reader struct {
+	t *Tomb
+	r io.Reader
+}
+
+func (r *reader) Read(p []byte) (n int, err error) {
+	if dying(r.t) {
+		return 0, ErrDying
+	}
+	n, err = r.r.Read(p)
+	if err != nil && dying(r.t) {
+		err = ErrDying
+	}
+	return n, err
+}
+
+type writer struct {
+	t *Tomb
+	w io.Writer
+}
+
+func (w *writer) Write(p []byte) (n int, err error) {
+	if dying(w.t) {
+		return 0, ErrDying
+	}
+	n, err = w.w.Write(p)
+	if err != nil && dying(w.t) {
+		err = ErrDying
+	}
+	return n, err
+}
+
+type readCloser struct {
+	*reader
+	c      io.Closer
+	remove func()
+}
+
+func (r *readCloser) Close() error {
+	r.remove()
+	return r.c.Close()
+}
+
+type writeCloser struct {
+	*writer
+	c      io.Closer
+	remove func()
+}
+
+func (w *writeCloser) Close() error {
+	w.remove()
+	return w.c.Close()
+}
@@ -0,0 +1,119 @@
+package tomb_test
+
+import (
+	"bytes"
+	"gopkg.in/tomb.v1"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	tb := &tomb.Tomb{}
+	r := tomb.Reader(tb, strings.NewReader("hello"))
+	buf := make([]byte, 2)
+	if n, err := r.Read(buf); n != 2 || err != nil {
+		t.Fatalf("Read: got %d, %v", n, err)
+	}
+	tb.Kill(nil)
+	if n, err := r.Read(buf); n != 0 || err != tomb.ErrDying {
+		t.Fatalf("Read: want ErrDying, got %d, %v", n, err)
+	}
+}
+
+func TestReaderUnblocks(t *testing.T) {
+	tb := &tomb.Tomb{}
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	r := tomb.Reader(tb, pr)
+	done := make(chan error)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		done <- err
+	}()
+	tb.Kill(nil)
+	if err := <-done; err != tomb.ErrDying {
+		t.Fatalf("Read: want ErrDying, got %v", err)
+	}
+}
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestReaderWriterClose(t *testing.T) {
+	tb := &tomb.Tomb{}
+	var rc, wc closeRecorder
+	if _, ok := tomb.Reader(tb, strings.NewReader("")).(io.Closer); ok {
+		t.Fatalf("Reader: want no Close for readers that aren't closers")
+	}
+	r := tomb.Reader(tb, &rc).(io.Closer)
+	w := tomb.Writer(tb, &wc).(io.Closer)
+	r.Close()
+	w.Close()
+	if !rc.closed || !wc.closed {
+		t.Fatalf("Close: underlying reader or writer not closed")
+	}
+
+	// closed wrappers no longer close on dying
+	rc.closed, wc.closed = false, false
+	tb.Kill(nil)
+	if rc.closed || wc.closed {
+		t.Fatalf("Kill: closed wrappers closed again")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tb := &tomb.Tomb{}
+	var buf bytes.Buffer
+	w := tomb.Writer(tb, &buf)
+	if n, err := w.Write([]byte("hi")); n != 2 || err != nil {
+		t.Fatalf("Write: got %d, %v", n, err)
+	}
+	tb.Kill(nil)
+	if n, err := w.Write([]byte("there")); n != 0 || err != tomb.ErrDying {
+		t.Fatalf("Write: want ErrDying, got %d, %v", n, err)
+	}
+	if buf.String() != "hi" {
+		t.Fatalf("Write: wrote %q", buf.String())
+	}
+}
+
+func TestCopy(t *testing.T) {
+	tb := &tomb.Tomb{}
+	var buf bytes.Buffer
+	n, err := tomb.Copy(tb, &buf, strings.NewReader("hello"))
+	if n != 5 || err != nil || buf.String() != "hello" {
+		t.Fatalf("Copy: got %d, %v, %q", n, err, buf.String())
+	}
+
+	// finished copies don't close anything on dying
+	other := &tomb.Tomb{}
+	var src, dst closeRecorder
+	src.WriteString("hello")
+	tomb.Copy(other, &dst, &src)
+	other.Kill(nil)
+	if src.closed || dst.closed {
+		t.Fatalf("Kill: closed src or dst of a finished Copy")
+	}
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	done := make(chan error)
+	go func() {
+		_, err := tomb.Copy(tb, &buf, pr)
+		done <- err
+	}()
+	pw.Write([]byte("more"))
+	tb.Kill(nil)
+	if err := <-done; err != tomb.ErrDying {
+		t.Fatalf("Copy: want ErrDying, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+)
+
+// Link couples the tombs a and b so that when either of them starts
+// dying, the other one is killed as well. If the dying tomb has an
+// error as the reason for its death, the other tomb is killed with
+// an error wrapping it.
+func Link(a, b *Tomb) {
+	agen, bgen := a.incarnation(), b.incarnation()
+	a.onDying(func(reason error) { b.killIncarnation(bgen, linkReason(reason), OriginLink) })
+	b.onDying(func(reason error) { a.killIncarnation(agen, linkReason(reason), OriginLink) })
+}
+
+// Follow arranges for t to be killed when other starts dying, but
+// not the other way around. If other has an error as the reason for
+// its death, t is killed with an error wrapping it. Once t starts
+// dying for any reason, other stops being watched.
+func (t *Tomb) Follow(other *Tomb) {
+	gen := t.incarnation()
+	remove := other.onDying(func(reason error) {
+		if reason != nil {
+			reason = fmt.Errorf("tomb: followed tomb died: %w", reason)
+		}
+		t.killIncarnation(gen, reason, OriginLink)
+	})
+	t.onDying(func(error) { remove() })
+}
+
+func linkReason(reason error) error {
+	if reason == nil {
+		return nil
+	}
+	return fmt.Errorf("tomb: linked tomb died: %w", reason)
+}
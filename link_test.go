@@ -0,0 +1,89 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLink(t *testing.T) {
+	a, b := &tomb.Tomb{}, &tomb.Tomb{}
+	tomb.Link(a, b)
+	testState(t, a, false, false, tomb.ErrStillAlive)
+	testState(t, b, false, false, tomb.ErrStillAlive)
+
+	err := errors.New("some error")
+	b.Kill(err)
+	testState(t, b, true, false, err)
+	if aerr := a.Err(); !errors.Is(aerr, err) || aerr.Error() != "tomb: linked tomb died: some error" {
+		t.Fatalf("Err: want linked error, got %#v", aerr)
+	}
+
+	// killing the other direction works the same way, and a
+	// clean death propagates as a clean death
+	a, b = &tomb.Tomb{}, &tomb.Tomb{}
+	tomb.Link(a, b)
+	a.Done()
+	testState(t, a, true, true, nil)
+	testState(t, b, true, false, nil)
+
+	// linking to a dying tomb kills the other one at once
+	a, b = &tomb.Tomb{}, &tomb.Tomb{}
+	a.Kill(err)
+	tomb.Link(a, b)
+	if !errors.Is(b.Err(), err) {
+		t.Fatalf("Err: want linked error, got %#v", b.Err())
+	}
+}
+
+func TestFollow(t *testing.T) {
+	main, aux := &tomb.Tomb{}, &tomb.Tomb{}
+	aux.Follow(main)
+
+	// the auxiliary tomb dying doesn't affect the main one
+	err := errors.New("some error")
+	aux.Kill(err)
+	testState(t, main, false, false, tomb.ErrStillAlive)
+
+	aux = &tomb.Tomb{}
+	aux.Follow(main)
+	main.Kill(err)
+	if aerr := aux.Err(); !errors.Is(aerr, err) || aerr.Error() != "tomb: followed tomb died: some error" {
+		t.Fatalf("Err: want followed error, got %#v", aerr)
+	}
+}
+
+func TestFollowReleasesHooks(t *testing.T) {
+	// a followed tomb dying after the follower is dead leaves it alone
+	main, aux := &tomb.Tomb{}, &tomb.Tomb{}
+	aux.Follow(main)
+	aux.Done()
+	main.Kill(errors.New("boom"))
+	testState(t, aux, true, true, nil)
+	if aux.Killed() {
+		t.Fatalf("Killed: dead follower killed by the followed tomb")
+	}
+
+	// and dead followers are no longer referenced by the followed
+	// tomb, so they don't pile up on it
+	main = &tomb.Tomb{}
+	collected := make(chan bool, 1)
+	func() {
+		aux := &tomb.Tomb{}
+		aux.Follow(main)
+		aux.Done()
+		runtime.SetFinalizer(aux, func(*tomb.Tomb) { collected <- true })
+	}()
+	defer runtime.KeepAlive(main)
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("Follow: dead follower still referenced by the followed tomb")
+}
@@ -0,0 +1,37 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWrapConn(t *testing.T) {
+	tb := &tomb.Tomb{}
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := tomb.WrapConn(tb, client)
+
+	done := make(chan error)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	tb.Kill(nil)
+	if err := <-done; err != io.ErrClosedPipe {
+		t.Fatalf("Read: want closed connection error, got %v", err)
+	}
+}
+
+func TestWrapConnClose(t *testing.T) {
+	tb := &tomb.Tomb{}
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := tomb.WrapConn(tb, client)
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	tb.Kill(nil)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"context"
+)
+
+// An Option configures a Tomb created with New.
+type Option func(t *Tomb)
+
+// New returns a new tomb configured with the provided options.
+// The zero value of a Tomb remains ready to use when no
+// configuration is needed.
+func New(opts ...Option) *Tomb {
+	t := &Tomb{}
+	t.init()
+	if len(opts) > 0 {
+		t.extra().opts = opts
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithName sets the name of the tomb, which is used in diagnostics.
+func WithName(name string) Option {
+	return func(t *Tomb) {
+		t.m.Lock()
+		t.extra().name = name
+		t.m.Unlock()
+	}
+}
+
+// WithParent makes the tomb follow parent, so that it is killed when
+// parent starts dying. See the Follow method for details.
+func WithParent(parent *Tomb) Option {
+	return func(t *Tomb) {
+		t.Follow(parent)
+	}
+}
+
+// WithParentContext binds the tomb to ctx the same way WithContext
+// does: the tomb is killed when ctx is done, and Context(nil) returns
+// a context derived from ctx.
+func WithParentContext(ctx context.Context) Option {
+	return func(t *Tomb) {
+		t.m.Lock()
+		t.extra().parent = ctx
+		t.m.Unlock()
+		t.KillOnContext(ctx)
+	}
+}
+
+// Name returns the name of the tomb as set via WithName.
+func (t *Tomb) Name() string {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x == nil {
+		return ""
+	}
+	return t.x.name
+}
+
+// Reincarnate returns a new tomb created with the same options that
+// were provided to New when creating t, so it has the same name, parent
+// and diagnostics configuration. Supervisors may use it to restart a
+// subsystem after it dies. Reincarnate panics if t is not dead.
+func (t *Tomb) Reincarnate() *Tomb {
+	t.m.Lock()
+	dead := t.deadAt != 0
+	var opts []Option
+	if t.x != nil {
+		opts = t.x.opts
+	}
+	t.m.Unlock()
+	if !dead {
+		panic("tomb: Reincarnate while not dead")
+	}
+	return New(opts...)
+}
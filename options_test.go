@@ -0,0 +1,68 @@
+package tomb_test
+
+import (
+	"context"
+	"errors"
+	"gopkg.in/tomb.v1"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tb := tomb.New()
+	if tb.Name() != "" {
+		t.Fatalf("Name: want empty, got %q", tb.Name())
+	}
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	parent := &tomb.Tomb{}
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	defer cancel()
+	tb = tomb.New(tomb.WithName("indexer"), tomb.WithParent(parent), tomb.WithParentContext(ctx))
+	if tb.Name() != "indexer" {
+		t.Fatalf("Name: want %q, got %q", "indexer", tb.Name())
+	}
+	if tb.Context(nil).Value(key{}) != "value" {
+		t.Fatalf("Context: parent context values not propagated")
+	}
+
+	err := errors.New("some error")
+	parent.Kill(err)
+	if !errors.Is(tb.Err(), err) {
+		t.Fatalf("Err: want followed error, got %#v", tb.Err())
+	}
+}
+
+func TestNewParentContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tb := tomb.New(tomb.WithParentContext(ctx))
+	cancel()
+	<-tb.Dying()
+	testState(t, tb, true, false, context.Canceled)
+}
+
+func TestReincarnate(t *testing.T) {
+	parent := &tomb.Tomb{}
+	tb := tomb.New(tomb.WithName("indexer"), tomb.WithParent(parent))
+	tb.Kill(errors.New("some error"))
+	tb.Done()
+
+	next := tb.Reincarnate()
+	if next == tb || next.Name() != "indexer" || next.ID() == tb.ID() {
+		t.Fatalf("Reincarnate: bad successor %d %q", next.ID(), next.Name())
+	}
+	testState(t, next, false, false, tomb.ErrStillAlive)
+
+	err := errors.New("parent error")
+	parent.Kill(err)
+	if !errors.Is(next.Err(), err) {
+		t.Fatalf("Err: want followed error, got %#v", next.Err())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Reincarnate: didn't panic on a tomb that isn't dead")
+		}
+	}()
+	next.Reincarnate()
+}
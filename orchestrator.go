@@ -0,0 +1,128 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// An Orchestrator shuts down a set of independent tombs in an order
+// that respects the dependencies between them. A tomb is only killed
+// once every tomb that depends on it is dead.
+//
+// The zero value of an Orchestrator is ready to use.
+type Orchestrator struct {
+	m     sync.Mutex
+	names []string
+	tombs map[string]*Tomb
+	deps  map[string][]string
+}
+
+// Add registers t under the given name, depending on the previously
+// registered tombs with the names in deps. It's a runtime error to
+// register the same name twice, or to depend on a name that was not
+// previously registered.
+func (o *Orchestrator) Add(name string, t *Tomb, deps ...string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if _, ok := o.tombs[name]; ok {
+		panic(fmt.Sprintf("tomb: orchestrator already has %q", name))
+	}
+	for _, dep := range deps {
+		if _, ok := o.tombs[dep]; !ok {
+			panic(fmt.Sprintf("tomb: orchestrator dependency %q of %q not registered", dep, name))
+		}
+	}
+	if o.tombs == nil {
+		o.tombs = make(map[string]*Tomb)
+		o.deps = make(map[string][]string)
+	}
+	o.names = append(o.names, name)
+	o.tombs[name] = t
+	o.deps[name] = append([]string(nil), deps...)
+}
+
+// Shutdown kills the registered tombs in reverse dependency order and
+// waits for each layer of tombs to be dead before killing the tombs
+// they depend on. The returned error joins the reasons for the death
+// of every tomb that died with an error, prefixed by the tomb name.
+//
+// If ctx is done before all tombs are dead, Shutdown returns without
+// killing the tombs that are still depended upon, and the returned
+// error also includes the context error.
+func (o *Orchestrator) Shutdown(ctx context.Context) error {
+	o.m.Lock()
+	names := append([]string(nil), o.names...)
+	tombs := make(map[string]*Tomb, len(names))
+	deps := make(map[string][]string, len(names))
+	for _, name := range names {
+		tombs[name] = o.tombs[name]
+		deps[name] = o.deps[name]
+	}
+	o.m.Unlock()
+
+	var errs []error
+	dead := make(map[string]bool)
+	for len(dead) < len(names) {
+		// A tomb is in the next layer if no tomb that is still
+		// alive depends on it.
+		needed := make(map[string]bool)
+		for _, name := range names {
+			if !dead[name] {
+				for _, dep := range deps[name] {
+					needed[dep] = true
+				}
+			}
+		}
+		var layer []string
+		for _, name := range names {
+			if !dead[name] && !needed[name] {
+				layer = append(layer, name)
+				tombs[name].Kill(nil)
+			}
+		}
+		for _, name := range layer {
+			t := tombs[name]
+			select {
+			case <-t.Dead():
+			case <-ctx.Done():
+				errs = append(errs, fmt.Errorf("tomb: %s still alive: %w", name, ctx.Err()))
+				return errors.Join(errs...)
+			}
+			if err := t.Wait(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			dead[name] = true
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package tomb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/tomb.v1"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrchestratorShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	worker := func(name string, err error) *tomb.Tomb {
+		tb := &tomb.Tomb{}
+		go func() {
+			<-tb.Dying()
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			tb.Kill(err)
+			tb.Done()
+		}()
+		return tb
+	}
+
+	dbErr := errors.New("db error")
+	var o tomb.Orchestrator
+	o.Add("db", worker("db", dbErr))
+	o.Add("cache", worker("cache", nil), "db")
+	o.Add("api", worker("api", nil), "db", "cache")
+
+	err := o.Shutdown(context.Background())
+	if !errors.Is(err, dbErr) || err.Error() != "db: db error" {
+		t.Fatalf("Shutdown: want db error, got %v", err)
+	}
+	if len(order) != 3 || order[0] != "api" || order[1] != "cache" || order[2] != "db" {
+		t.Fatalf("Shutdown: wrong order: %v", order)
+	}
+}
+
+func TestOrchestratorShutdownTimeout(t *testing.T) {
+	db := &tomb.Tomb{}
+	stuck := &tomb.Tomb{}
+
+	var o tomb.Orchestrator
+	o.Add("db", db)
+	o.Add("stuck", stuck, "db")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := o.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown: want deadline exceeded, got %v", err)
+	}
+	testState(t, stuck, true, false, nil)
+	testState(t, db, false, false, tomb.ErrStillAlive)
+}
+
+func TestOrchestratorAddDuringShutdown(t *testing.T) {
+	// tombs added while shutting down are left for a later Shutdown
+	var o tomb.Orchestrator
+	for i := 0; i < 10; i++ {
+		tb := &tomb.Tomb{}
+		tb.Done()
+		o.Add(fmt.Sprint("done", i), tb)
+	}
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		for i := 0; i < 10; i++ {
+			o.Add(fmt.Sprint("late", i), &tomb.Tomb{})
+		}
+	}()
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	<-added
+}
+
+func TestOrchestratorAddUnknownDependency(t *testing.T) {
+	var o tomb.Orchestrator
+	defer func() {
+		err := recover()
+		if err != `tomb: orchestrator dependency "db" of "api" not registered` {
+			t.Fatalf("Wrong panic on Add: %v", err)
+		}
+	}()
+	o.Add("api", &tomb.Tomb{}, "db")
+}
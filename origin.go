@@ -0,0 +1,123 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// OriginKind identifies what caused a tomb to start dying.
+type OriginKind int
+
+const (
+	// OriginNone means the tomb is still alive.
+	OriginNone OriginKind = iota
+	// OriginKill means Kill, Killf or KillCause was called.
+	OriginKill
+	// OriginDone means Done was called without the tomb being killed first.
+	OriginDone
+	// OriginLink means a tomb associated via Link or Follow died.
+	OriginLink
+	// OriginTrigger means a channel or context provided to KillOn,
+	// KillOnContext or WithContext fired.
+	OriginTrigger
+	// OriginTimeout means a timer, such as the one set up via
+	// WithIdleKill, fired.
+	OriginTimeout
+)
+
+func (k OriginKind) String() string {
+	switch k {
+	case OriginNone:
+		return "none"
+	case OriginKill:
+		return "kill"
+	case OriginDone:
+		return "done"
+	case OriginLink:
+		return "link"
+	case OriginTrigger:
+		return "trigger"
+	case OriginTimeout:
+		return "timeout"
+	}
+	return fmt.Sprintf("OriginKind(%d)", int(k))
+}
+
+// Origin describes what caused a tomb to start dying.
+type Origin struct {
+	Kind OriginKind
+
+	// Caller holds the file and line of the call to Kill, Killf,
+	// KillCause or Done that caused the death, when applicable.
+	Caller string
+}
+
+func (o Origin) String() string {
+	if o.Caller == "" {
+		return o.Kind.String()
+	}
+	return o.Kind.String() + " at " + o.Caller
+}
+
+// callerPC returns the program counter of the caller that many
+// frames above callerPC's caller.
+func callerPC(skip int) uintptr {
+	var pc [1]uintptr
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return 0
+	}
+	return pc[0]
+}
+
+// deathOrigin returns the Origin for t. It must be called with t.m held.
+func (t *Tomb) deathOrigin() Origin {
+	o := Origin{Kind: t.originKind}
+	if t.originPC != 0 {
+		o.Caller = pcLocation(t.originPC)
+	}
+	return o
+}
+
+// pcLocation returns the file and line for the program counter pc,
+// as returned by callerPC.
+func pcLocation(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
+
+// DeathOrigin returns what caused t to start dying, or an Origin of
+// kind OriginNone if t is still alive.
+func (t *Tomb) DeathOrigin() Origin {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.deathOrigin()
+}
@@ -0,0 +1,47 @@
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"strings"
+	"testing"
+)
+
+func TestDeathOrigin(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginNone || o.Caller != "" {
+		t.Fatalf("DeathOrigin: want none while alive, got %v", o)
+	}
+
+	tb.Killf("BOOM")
+	o := tb.DeathOrigin()
+	if o.Kind != tomb.OriginKill || !strings.Contains(o.Caller, "origin_test.go:") {
+		t.Fatalf("DeathOrigin: want kill from origin_test.go, got %v", o)
+	}
+	tb.Done()
+	if tb.DeathOrigin() != o || tb.Snapshot().Origin != o {
+		t.Fatalf("DeathOrigin: changed by Done")
+	}
+
+	tb = &tomb.Tomb{}
+	tb.Done()
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginDone || !strings.Contains(o.Caller, "origin_test.go:") {
+		t.Fatalf("DeathOrigin: want done from origin_test.go, got %v", o)
+	}
+
+	a, b := &tomb.Tomb{}, &tomb.Tomb{}
+	b.Follow(a)
+	a.Kill(errors.New("some error"))
+	if o := b.DeathOrigin(); o.Kind != tomb.OriginLink || o.String() != "link" {
+		t.Fatalf("DeathOrigin: want link, got %v", o)
+	}
+
+	tb = &tomb.Tomb{}
+	ch := make(chan struct{})
+	tb.KillOn(nil, ch)
+	close(ch)
+	<-tb.Dying()
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginTrigger {
+		t.Fatalf("DeathOrigin: want trigger, got %v", o)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+// Pausing returns a channel that is closed while t is paused via
+// Pause. Goroutines that support pausing may select on it to stop
+// consuming work temporarily, and then wait on Resumed before
+// carrying on. Pausing has no effect on the tomb state.
+func (t *Tomb) Pausing() <-chan struct{} {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if x.pausing == nil {
+		x.pausing = make(chan struct{})
+		if x.paused {
+			close(x.pausing)
+		}
+	}
+	return x.pausing
+}
+
+// Resumed returns a channel that is closed while t is not paused.
+func (t *Tomb) Resumed() <-chan struct{} {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if x.resumed == nil {
+		x.resumed = make(chan struct{})
+		if !x.paused {
+			close(x.resumed)
+		}
+	}
+	return x.resumed
+}
+
+// Pause flags t as paused, closing the channel returned by Pausing.
+// Calling Pause on a paused tomb has no effect.
+func (t *Tomb) Pause() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if x.paused {
+		return
+	}
+	x.paused = true
+	t.record(EventPause, nil)
+	if x.pausing != nil {
+		close(x.pausing)
+	}
+	x.resumed = nil
+}
+
+// Resume flags t as no longer paused, closing the channel returned
+// by Resumed. Calling Resume on a tomb that isn't paused has no effect.
+func (t *Tomb) Resume() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if !x.paused {
+		return
+	}
+	x.paused = false
+	t.record(EventResume, nil)
+	if x.resumed != nil {
+		close(x.resumed)
+	}
+	x.pausing = nil
+}
@@ -0,0 +1,56 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"testing"
+)
+
+func TestPause(t *testing.T) {
+	tb := &tomb.Tomb{}
+	testPaused(t, tb, false)
+
+	pausing := tb.Pausing()
+	tb.Pause()
+	<-pausing
+	testPaused(t, tb, true)
+
+	// pausing twice is harmless
+	tb.Pause()
+	testPaused(t, tb, true)
+
+	resumed := tb.Resumed()
+	tb.Resume()
+	<-resumed
+	testPaused(t, tb, false)
+
+	// resuming twice is harmless
+	tb.Resume()
+	testPaused(t, tb, false)
+
+	// pausing doesn't affect the tomb state
+	tb.Pause()
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+}
+
+func testPaused(t *testing.T, tb *tomb.Tomb, wantPaused bool) {
+	select {
+	case <-tb.Pausing():
+		if !wantPaused {
+			t.Error("<-Pausing: should block")
+		}
+	default:
+		if wantPaused {
+			t.Error("<-Pausing: should not block")
+		}
+	}
+	select {
+	case <-tb.Resumed():
+		if wantPaused {
+			t.Error("<-Resumed: should block")
+		}
+	default:
+		if !wantPaused {
+			t.Error("<-Resumed: should not block")
+		}
+	}
+}
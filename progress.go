@@ -0,0 +1,74 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import "time"
+
+// SetTotal sets the number of steps the goroutine expects to complete,
+// for reporting via Progress, Snapshot and LastEvents.
+func (t *Tomb) SetTotal(n int) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.extra().stepsTotal = n
+	t.recordProgress()
+}
+
+// StepDone records that one more step was completed by the goroutine.
+// An EventProgress event is only recorded once every step set via
+// SetTotal is done, so that long batches don't flood the event trace.
+func (t *Tomb) StepDone() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	x.stepsDone++
+	if x.stepsDone == x.stepsTotal {
+		t.recordProgress()
+	}
+}
+
+// Progress returns how many steps were reported done via StepDone, and
+// the total set via SetTotal.
+func (t *Tomb) Progress() (done, total int) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x == nil {
+		return 0, 0
+	}
+	return t.x.stepsDone, t.x.stepsTotal
+}
+
+// recordProgress records an EventProgress event with the current
+// progress if event tracing is enabled. It must be called with t.m held.
+func (t *Tomb) recordProgress() {
+	if t.tracing() {
+		r := t.x.events
+		r.buf[r.n%len(r.buf)] = Event{Time: time.Now(), Kind: EventProgress, Done: t.x.stepsDone, Total: t.x.stepsTotal}
+		r.n++
+	}
+}
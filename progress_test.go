@@ -0,0 +1,36 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tb := tomb.New(tomb.WithEventTrace(4))
+	if done, total := tb.Progress(); done != 0 || total != 0 {
+		t.Fatalf("Progress: want 0/0, got %d/%d", done, total)
+	}
+	tb.SetTotal(3)
+	tb.StepDone()
+	tb.StepDone()
+	if done, total := tb.Progress(); done != 2 || total != 3 {
+		t.Fatalf("Progress: want 2/3, got %d/%d", done, total)
+	}
+	if len(tb.LastEvents(0)) != 1 {
+		t.Fatalf("LastEvents: want only the SetTotal event, got %v", tb.LastEvents(0))
+	}
+	tb.StepDone()
+
+	s := tb.Snapshot()
+	if s.StepsDone != 3 || s.StepsTotal != 3 {
+		t.Fatalf("Snapshot: want 3/3 steps, got %d/%d", s.StepsDone, s.StepsTotal)
+	}
+	events := tb.LastEvents(0)
+	if len(events) != 2 || events[1].Kind != tomb.EventProgress || events[1].Done != 3 || events[1].Total != 3 {
+		t.Fatalf("LastEvents: want progress events, got %v", events)
+	}
+	if !strings.HasSuffix(events[1].String(), "progress: 3/3") {
+		t.Fatalf("Event.String: got %q", events[1].String())
+	}
+}
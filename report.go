@@ -0,0 +1,119 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A DeathReport describes how a tomb died.
+type DeathReport struct {
+	// ID holds the tomb ID, as returned by the ID method.
+	ID uint64
+
+	// Name holds the tomb name as set via WithName.
+	Name string
+
+	// Reason holds the reason for the goroutine death, as returned by Wait.
+	Reason error
+
+	// Suppressed holds the errors provided to Kill after Reason had
+	// already been recorded, in the order they were provided.
+	Suppressed []error
+
+	Origin  Origin
+	DyingAt time.Time
+	DeadAt  time.Time
+}
+
+// ShutdownDuration returns how long the goroutine took to terminate
+// after it was flagged as dying.
+func (r *DeathReport) ShutdownDuration() time.Duration {
+	return r.DeadAt.Sub(r.DyingAt)
+}
+
+// String returns a human readable description of the report.
+func (r *DeathReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tomb %d", r.ID)
+	if r.Name != "" {
+		fmt.Fprintf(&b, " %q", r.Name)
+	}
+	if r.Reason == nil {
+		b.WriteString(" stopped")
+	} else {
+		fmt.Fprintf(&b, " died: %v", r.Reason)
+	}
+	fmt.Fprintf(&b, "\norigin: %v", r.Origin)
+	fmt.Fprintf(&b, "\nshutdown took %v", r.ShutdownDuration())
+	for _, err := range r.Suppressed {
+		fmt.Fprintf(&b, "\nsuppressed: %v", err)
+	}
+	return b.String()
+}
+
+// Report returns a report describing how t died, or nil if t is
+// not yet dead.
+func (t *Tomb) Report() *DeathReport {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.deadAt == 0 {
+		return nil
+	}
+	return t.report(t.deadAt)
+}
+
+// MustWait blocks until the goroutine is in a dead state, and panics
+// with the death report if it died with an error. It is meant for
+// tools and tests in which any failure should abort loudly.
+func (t *Tomb) MustWait() {
+	if t.Wait() != nil {
+		panic(t.Report().String())
+	}
+}
+
+// report returns a report for t dying at the provided time.
+// It must be called with t.m held.
+func (t *Tomb) report(deadAt stamp) *DeathReport {
+	r := &DeathReport{
+		ID:      t.id,
+		Reason:  t.reason,
+		Origin:  t.deathOrigin(),
+		DyingAt: t.dyingAt.time(),
+		DeadAt:  deadAt.time(),
+	}
+	if t.x != nil {
+		r.Name = t.x.name
+		r.Suppressed = append([]error(nil), t.x.suppressed...)
+	}
+	return r
+}
@@ -0,0 +1,74 @@
+package tomb_test
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/tomb.v1"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	tb := tomb.New(tomb.WithName("indexer"))
+	first := errors.New("first error")
+	second := errors.New("second error")
+	tb.Kill(first)
+	tb.Kill(second)
+	tb.Kill(first)
+	if r := tb.Report(); r != nil {
+		t.Fatalf("Report: want nil before death, got %v", r)
+	}
+	tb.Done()
+
+	r := tb.Report()
+	if r.Name != "indexer" || r.Reason != first || r.Origin.Kind != tomb.OriginKill {
+		t.Fatalf("Report: bad report: %#v", r)
+	}
+	if len(r.Suppressed) != 1 || r.Suppressed[0] != second {
+		t.Fatalf("Report: want second error suppressed, got %v", r.Suppressed)
+	}
+	if r.ShutdownDuration() < 0 || r.DeadAt != tb.DeadAt() {
+		t.Fatalf("Report: bad times: %v, %v", r.DyingAt, r.DeadAt)
+	}
+	s := r.String()
+	if !strings.HasPrefix(s, fmt.Sprintf(`tomb %d "indexer" died: first error`, tb.ID())+"\norigin: kill at ") ||
+		!strings.HasSuffix(s, "\nsuppressed: second error") {
+		t.Fatalf("String: got %q", s)
+	}
+
+	tb = &tomb.Tomb{}
+	tb.Done()
+	if s := tb.Report().String(); !strings.HasPrefix(s, fmt.Sprintf("tomb %d stopped\norigin: done at ", tb.ID())) {
+		t.Fatalf("String: got %q", s)
+	}
+}
+
+func TestReportLinked(t *testing.T) {
+	// errors bouncing back through a link are not suppressed errors
+	a, b := &tomb.Tomb{}, &tomb.Tomb{}
+	tomb.Link(a, b)
+	a.Kill(errors.New("some error"))
+	a.Done()
+	if r := a.Report(); len(r.Suppressed) != 0 {
+		t.Fatalf("Report: unexpected suppressed errors: %v", r.Suppressed)
+	}
+}
+
+func TestMustWait(t *testing.T) {
+	tb := &tomb.Tomb{}
+	tb.Kill(nil)
+	tb.Done()
+	tb.MustWait()
+
+	tb = tomb.New(tomb.WithName("indexer"))
+	tb.Kill(errors.New("some error"))
+	tb.Done()
+	defer func() {
+		s, _ := recover().(string)
+		if !strings.HasPrefix(s, fmt.Sprintf(`tomb %d "indexer" died: some error`, tb.ID())) {
+			t.Fatalf("MustWait: want death report in panic, got %q", s)
+		}
+	}()
+	tb.MustWait()
+	t.Fatalf("MustWait: didn't panic")
+}
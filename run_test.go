@@ -0,0 +1,39 @@
+package tomb_test
+
+import (
+	"context"
+	"errors"
+	"gopkg.in/tomb.v1"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var tb *tomb.Tomb
+	err := tomb.Run(context.Background(), func(t *tomb.Tomb) error {
+		tb = t
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run: want nil, got %v", err)
+	}
+	testState(t, tb, true, true, nil)
+
+	want := errors.New("some error")
+	err = tomb.Run(context.Background(), func(t *tomb.Tomb) error {
+		return want
+	})
+	if err != want {
+		t.Fatalf("Run: want %v, got %v", want, err)
+	}
+
+	// the context being canceled puts the tomb in a dying state
+	ctx, cancel := context.WithCancel(context.Background())
+	err = tomb.Run(ctx, func(t *tomb.Tomb) error {
+		cancel()
+		<-t.Dying()
+		return tomb.ErrDying
+	})
+	if err != context.Canceled {
+		t.Fatalf("Run: want context.Canceled, got %v", err)
+	}
+}
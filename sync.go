@@ -0,0 +1,116 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"sync"
+	"time"
+)
+
+// Acquire acquires n slots from the semaphore sem by sending n values
+// on it, where the capacity of sem is the semaphore weight, blocking
+// until all slots are acquired or t starts dying. In the latter case
+// the slots acquired so far are released and ErrDying is returned.
+// Slots are released by receiving from sem.
+func (t *Tomb) Acquire(sem chan struct{}, n int) error {
+	dying := t.Dying()
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-dying:
+			for ; i > 0; i-- {
+				<-sem
+			}
+			return ErrDying
+		}
+	}
+	return nil
+}
+
+// LockOrDying locks l, blocking until the lock is acquired or t starts
+// dying, in which case ErrDying is returned and l is not held. If the
+// lock is acquired after the call has given up, a helper goroutine
+// releases it again right away.
+func LockOrDying(t *Tomb, l sync.Locker) error {
+	if tl, ok := l.(interface{ TryLock() bool }); ok && tl.TryLock() {
+		return nil
+	}
+	dying := t.Dying()
+	var m sync.Mutex
+	var abandoned bool
+	acquired := make(chan struct{})
+	go func() {
+		l.Lock()
+		m.Lock()
+		if abandoned {
+			l.Unlock()
+		} else {
+			close(acquired)
+		}
+		m.Unlock()
+	}()
+	select {
+	case <-acquired:
+		return nil
+	case <-dying:
+	}
+	m.Lock()
+	defer m.Unlock()
+	select {
+	case <-acquired:
+		l.Unlock()
+	default:
+		abandoned = true
+	}
+	return ErrDying
+}
+
+// WaitUntil blocks until cond returns true, or returns ErrDying if
+// t starts dying first. cond is checked once at first, and then again
+// each time a value is received from notify, and every poll duration
+// if poll is positive. Either notify may be nil, or poll zero, but
+// not both.
+func (t *Tomb) WaitUntil(cond func() bool, notify <-chan struct{}, poll time.Duration) error {
+	dying := t.Dying()
+	var tick <-chan time.Time
+	if poll > 0 {
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for !cond() {
+		select {
+		case <-notify:
+		case <-tick:
+		case <-dying:
+			return ErrDying
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	tb := &tomb.Tomb{}
+	sem := make(chan struct{}, 3)
+	if err := tb.Acquire(sem, 2); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if len(sem) != 2 {
+		t.Fatalf("Acquire: want 2 slots taken, got %d", len(sem))
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- tb.Acquire(sem, 2)
+	}()
+	tb.Kill(nil)
+	if err := <-done; err != tomb.ErrDying {
+		t.Fatalf("Acquire: want ErrDying, got %v", err)
+	}
+	if len(sem) != 2 {
+		t.Fatalf("Acquire: partial acquisition not released, %d slots taken", len(sem))
+	}
+}
+
+func TestLockOrDying(t *testing.T) {
+	tb := &tomb.Tomb{}
+	var m sync.Mutex
+	if err := tomb.LockOrDying(tb, &m); err != nil {
+		t.Fatalf("LockOrDying: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- tomb.LockOrDying(tb, &m)
+	}()
+	tb.Kill(nil)
+	if err := <-done; err != tomb.ErrDying {
+		t.Fatalf("LockOrDying: want ErrDying, got %v", err)
+	}
+
+	// the abandoned acquisition doesn't keep the lock
+	m.Unlock()
+	m.Lock()
+	m.Unlock()
+}
+
+func TestLockOrDyingLocker(t *testing.T) {
+	// lockers without TryLock work too
+	tb := &tomb.Tomb{}
+	var m sync.RWMutex
+	if err := tomb.LockOrDying(tb, m.RLocker()); err != nil {
+		t.Fatalf("LockOrDying: %v", err)
+	}
+	m.RUnlock()
+}
+
+func TestWaitUntil(t *testing.T) {
+	tb := &tomb.Tomb{}
+	var v int32
+	notify := make(chan struct{})
+	go func() {
+		atomic.StoreInt32(&v, 1)
+		notify <- struct{}{}
+	}()
+	if err := tb.WaitUntil(func() bool { return atomic.LoadInt32(&v) == 1 }, notify, 0); err != nil {
+		t.Fatalf("WaitUntil: %v", err)
+	}
+
+	// polling notices changes without notifications
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt32(&v, 2)
+	}()
+	if err := tb.WaitUntil(func() bool { return atomic.LoadInt32(&v) == 2 }, nil, time.Millisecond); err != nil {
+		t.Fatalf("WaitUntil: %v", err)
+	}
+
+	go tb.Kill(nil)
+	if err := tb.WaitUntil(func() bool { return false }, nil, 0); err != tomb.ErrDying {
+		t.Fatalf("WaitUntil: want ErrDying, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"sync"
+	"time"
+)
+
+// A Timer is a timer bound to a tomb, created via NewTimer. Unlike with
+// a plain time.Timer, stopping or resetting it discards any value not yet
+// received from C, and once the tomb starts dying an active timer is
+// stopped and drained for good, so workers never see a late expiration.
+// An expiration sent on C before the tomb started dying is left there
+// for the worker to receive, as the timer lets go of the tomb once it
+// fires.
+type Timer struct {
+	// C receives the current time once the timer expires.
+	C <-chan time.Time
+
+	t      *Tomb
+	c      chan time.Time
+	m      sync.Mutex
+	timer  *time.Timer
+	gen    int // Incremented every time the timer is stopped.
+	armed  bool
+	dying  bool
+	remove func() // Removes the dying hook; set only while armed.
+}
+
+// NewTimer returns a new Timer that sends the current time on its
+// channel after at least duration d, unless t starts dying first.
+func (t *Tomb) NewTimer(d time.Duration) *Timer {
+	c := make(chan time.Time, 1)
+	tm := &Timer{C: c, t: t, c: c}
+	tm.m.Lock()
+	tm.start(d)
+	tm.m.Unlock()
+	tm.hook()
+	return tm
+}
+
+// Stop prevents the timer from firing, and discards any expiration not
+// yet received from C. It returns true if the call stops the timer, and
+// false if the timer had already expired or been stopped.
+func (tm *Timer) Stop() bool {
+	tm.m.Lock()
+	active := tm.stop()
+	remove := tm.remove
+	tm.remove = nil
+	tm.m.Unlock()
+	if remove != nil {
+		remove()
+	}
+	return active
+}
+
+// Reset changes the timer to expire after duration d, discarding any
+// expiration not yet received from C. It returns true if the timer had
+// been active. Once the tomb is dying, Reset does nothing and returns false.
+func (tm *Timer) Reset(d time.Duration) bool {
+	tm.m.Lock()
+	if tm.dying {
+		tm.m.Unlock()
+		return false
+	}
+	active := tm.stop()
+	tm.start(d)
+	hooked := tm.remove != nil
+	tm.m.Unlock()
+	if !hooked {
+		tm.hook()
+	}
+	return active
+}
+
+// start arms the timer. It must be called with tm.m held.
+func (tm *Timer) start(d time.Duration) {
+	gen := tm.gen
+	tm.armed = true
+	tm.timer = time.AfterFunc(d, func() {
+		tm.m.Lock()
+		if gen != tm.gen || tm.dying {
+			tm.m.Unlock()
+			return
+		}
+		select {
+		case tm.c <- time.Now():
+		default:
+		}
+		// Nothing is left to stop, so don't keep the tomb hook
+		// around until a Reset arms the timer again.
+		tm.armed = false
+		remove := tm.remove
+		tm.remove = nil
+		tm.m.Unlock()
+		if remove != nil {
+			remove()
+		}
+	})
+}
+
+// stop disarms the timer and drains its channel.
+// It must be called with tm.m held.
+func (tm *Timer) stop() bool {
+	active := tm.timer.Stop()
+	tm.armed = false
+	tm.gen++
+	select {
+	case <-tm.c:
+	default:
+	}
+	return active
+}
+
+// hook arranges for the timer to be stopped when the tomb starts dying,
+// unless the timer is no longer armed by the time the hook is in place.
+func (tm *Timer) hook() {
+	remove := tm.t.onDying(func(error) {
+		tm.m.Lock()
+		defer tm.m.Unlock()
+		tm.dying = true
+		tm.remove = nil
+		tm.stop()
+	})
+	tm.m.Lock()
+	if tm.remove == nil && tm.armed && !tm.dying {
+		tm.remove = remove
+		remove = nil
+	}
+	tm.m.Unlock()
+	if remove != nil {
+		remove()
+	}
+}
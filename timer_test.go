@@ -0,0 +1,84 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	tb := &tomb.Tomb{}
+	tm := tb.NewTimer(time.Millisecond)
+	select {
+	case <-tm.C:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timer: didn't fire")
+	}
+
+	// stale expirations are discarded by Reset
+	tm.Reset(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if tm.Reset(time.Hour) {
+		t.Fatalf("Reset: want false on expired timer")
+	}
+	select {
+	case <-tm.C:
+		t.Fatalf("Timer: stale expiration received after Reset")
+	default:
+	}
+	if !tm.Stop() {
+		t.Fatalf("Stop: want true on active timer")
+	}
+	if tm.Stop() {
+		t.Fatalf("Stop: want false on stopped timer")
+	}
+}
+
+func TestTimerDying(t *testing.T) {
+	tb := &tomb.Tomb{}
+	tm := tb.NewTimer(10 * time.Millisecond)
+	tb.Kill(nil)
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-tm.C:
+		t.Fatalf("Timer: expiration received after dying")
+	default:
+	}
+	if tm.Reset(time.Millisecond) {
+		t.Fatalf("Reset: want false after dying")
+	}
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-tm.C:
+		t.Fatalf("Timer: fired after Reset while dying")
+	default:
+	}
+
+	// timers created while dying never fire
+	tm = tb.NewTimer(0)
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-tm.C:
+		t.Fatalf("Timer: fired while dying")
+	default:
+	}
+}
+
+func TestTimerReleasesHook(t *testing.T) {
+	// timers that fired are no longer referenced by their tomb,
+	// so they don't pile up on it
+	tb := &tomb.Tomb{}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 10000; i++ {
+		<-tb.NewTimer(0).C
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(tb)
+	if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > 512<<10 {
+		t.Fatalf("Timer: fired timers still referenced by their tomb, heap grew by %d bytes", grown)
+	}
+}
@@ -61,9 +61,13 @@
 package tomb
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // A Tomb tracks the lifecycle of a goroutine as alive, dying or dead,
@@ -71,31 +75,198 @@ import (
 //
 // See the package documentation for details.
 type Tomb struct {
-	m      sync.Mutex
-	dying  chan struct{}
-	dead   chan struct{}
-	reason error
+	m       sync.Mutex
+	inited  bool
+	killed  bool
+	gen     uint32 // Incremented by Reset.
+	id      uint64
+	dying   chan struct{}
+	dead    chan struct{}
+	reason  error
+	dyingAt stamp
+	deadAt  stamp
+
+	doneCalled  bool
+	closingDead bool
+	originKind  OriginKind
+	originPC    uintptr
+	kills       int
+	firstKill   stamp
+	lastKill    stamp
+
+	x *tombExtra
+}
+
+// tombExtra holds the state of a Tomb that is only needed by optional
+// features, allocated on first use so that plain tombs stay small.
+type tombExtra struct {
+	name       string
+	opts       []Option
+	ready      chan struct{}
+	suppressed []error
+
+	paused  bool
+	pausing chan struct{}
+	resumed chan struct{}
+
+	stepsDone  int
+	stepsTotal int
+
+	parent      context.Context
+	child       map[context.Context]childContext
+	dyingHooks  []*dyingHook
+	deadClosers []*deadCloser
+	deathSubs   []chan error
+	crashDump   io.Writer
+	profileHook func(p *DeathProfile)
+	profileHeap bool
+	events      *eventRing
+	idleTimer   *time.Timer
+	idleTimeout time.Duration
+
+	// busy counts the shutdown work in progress that Done must wait
+	// for, such as dying hooks run by the goroutine that called Kill,
+	// and idle is closed once that count drops to zero.
+	busy int
+	idle chan struct{}
+}
+
+// extra returns the optional state of t, allocating it if needed.
+// It must be called with t.m held.
+func (t *Tomb) extra() *tombExtra {
+	if t.x == nil {
+		t.x = &tombExtra{}
+	}
+	return t.x
+}
+
+// A stamp is a point in time stored as the time elapsed since epoch,
+// which keeps the monotonic clock reading in half the space of a
+// time.Time. The zero stamp means the time is unset.
+type stamp int64
+
+// epoch is the reference point for stamps.
+var epoch = time.Now()
+
+// stampOf returns the stamp for tm, which must not precede epoch.
+func stampOf(tm time.Time) stamp {
+	if s := stamp(tm.Sub(epoch)); s > 0 {
+		return s
+	}
+	return 1
+}
+
+// time returns the time for s, or the zero time if s is unset.
+func (s stamp) time() time.Time {
+	if s == 0 {
+		return time.Time{}
+	}
+	return epoch.Add(time.Duration(s))
+}
+
+type dyingHook struct {
+	f func(reason error)
+}
+
+type childContext struct {
+	context context.Context
+	cancel  context.CancelCauseFunc
+}
+
+// State represents the lifecycle stage of the goroutine tracked by a Tomb.
+type State int
+
+const (
+	Alive State = iota
+	Dying
+	Dead
+)
+
+func (s State) String() string {
+	switch s {
+	case Alive:
+		return "alive"
+	case Dying:
+		return "dying"
+	case Dead:
+		return "dead"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
 }
 
 var (
 	ErrStillAlive = errors.New("tomb: still alive")
-	ErrDying = errors.New("tomb: dying")
+	ErrDying      = errors.New("tomb: dying")
+	ErrStopped    = errors.New("tomb: stopped")
+	ErrTimeout    = errors.New("tomb: timeout")
 )
 
+// closedChan is used in place of the dying and dead channels of
+// tombs that change state before anyone asks for them, so that
+// short-lived tombs need not allocate them.
+var closedChan = make(chan struct{})
+
+func init() {
+	close(closedChan)
+}
+
+// dyingError is the type of the errors returned by the ErrDying method.
+type dyingError struct {
+	t *Tomb
+}
+
+func (e dyingError) Error() string {
+	return ErrDying.Error()
+}
+
+func (e dyingError) Is(target error) bool {
+	return target == ErrDying
+}
+
+// ErrDying returns an error that works like the package ErrDying for
+// t, and matches ErrDying via errors.Is. Unlike ErrDying, providing it
+// to the Kill method of any other tomb records it as a real reason for
+// death, so a dying error leaking from an outer tomb into an inner one
+// is not silently taken as a clean termination.
+func (t *Tomb) ErrDying() error {
+	return dyingError{t}
+}
+
+// lastID holds the last ID assigned to a tomb.
+var lastID uint64
+
 func (t *Tomb) init() {
 	t.m.Lock()
-	if t.dead == nil {
-		t.dead = make(chan struct{})
-		t.dying = make(chan struct{})
+	if !t.inited {
+		t.inited = true
+		t.id = atomic.AddUint64(&lastID, 1)
 		t.reason = ErrStillAlive
 	}
 	t.m.Unlock()
 }
 
+// ID returns a number that uniquely identifies t within the process.
+// IDs are assigned in increasing order as tombs are first used.
+func (t *Tomb) ID() uint64 {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.id
+}
+
 // Dead returns the channel that can be used to wait
 // until t.Done has been called.
 func (t *Tomb) Dead() <-chan struct{} {
 	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.dead == nil {
+		if t.deadAt == 0 {
+			t.dead = make(chan struct{})
+		} else {
+			t.dead = closedChan
+		}
+	}
 	return t.dead
 }
 
@@ -103,68 +274,622 @@ func (t *Tomb) Dead() <-chan struct{} {
 // until t.Kill or t.Done has been called.
 func (t *Tomb) Dying() <-chan struct{} {
 	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.dying == nil {
+		if t.dyingAt == 0 {
+			t.dying = make(chan struct{})
+		} else {
+			t.dying = closedChan
+		}
+	}
 	return t.dying
 }
 
+// Ready returns the channel that can be used to wait
+// until t.MarkReady has been called.
+func (t *Tomb) Ready() <-chan struct{} {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if x.ready == nil {
+		x.ready = make(chan struct{})
+	}
+	return x.ready
+}
+
+// MarkReady flags the goroutine as ready, meaning it is not only
+// running but also fully able to do its job, such as serving requests.
+// MarkReady may be called multiple times, and has no effect on the
+// tomb state.
+func (t *Tomb) MarkReady() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if x.ready == nil {
+		x.ready = make(chan struct{})
+	}
+	select {
+	case <-x.ready:
+	default:
+		close(x.ready)
+		t.record(EventReady, nil)
+	}
+}
+
 // Wait blocks until the goroutine is in a dead state and returns the
 // reason for its death.
 func (t *Tomb) Wait() error {
-	t.init()
-	<-t.dead
+	<-t.Dead()
 	t.m.Lock()
 	reason := t.reason
 	t.m.Unlock()
 	return reason
 }
 
+// WaitDying blocks until the goroutine is in a dying state and returns
+// the reason for its death as known at that point, which may be nil.
+// If ctx is done first, WaitDying returns the context error instead.
+func (t *Tomb) WaitDying(ctx context.Context) error {
+	select {
+	case <-t.Dying():
+		return t.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NotifyDead arranges for the reason for the goroutine death to be
+// sent on ch once the goroutine is dead. The send is performed by a
+// separate goroutine, so a slow receiver never blocks t. The reason
+// sent is the one of the goroutine tracked when NotifyDead is called,
+// even if t is Reset before the send happens.
+func (t *Tomb) NotifyDead(ch chan<- error) {
+	death := t.SubscribeDeath()
+	go func() {
+		ch <- <-death
+	}()
+}
+
+// SubscribeDeath returns a channel that delivers the reason for the
+// goroutine death exactly once, when the goroutine is dead, and is
+// then closed. The channel is buffered, so delivery never blocks t
+// and needs no extra goroutine per subscriber.
+func (t *Tomb) SubscribeDeath() <-chan error {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	ch := make(chan error, 1)
+	if t.deadAt == 0 {
+		x := t.extra()
+		x.deathSubs = append(x.deathSubs, ch)
+	} else {
+		ch <- t.reason
+		close(ch)
+	}
+	return ch
+}
+
+// Stop flags the goroutine as dying with no error and blocks until
+// it is dead, returning the reason for its death. It is the conventional
+// way for the owner of a goroutine to request a graceful shutdown.
+func (t *Tomb) Stop() error {
+	t.Kill(nil)
+	return t.Wait()
+}
+
+// Close is equivalent to Stop. It allows a Tomb to be used where
+// an io.Closer is expected.
+func (t *Tomb) Close() error {
+	return t.Stop()
+}
+
 // Done flags the goroutine as dead, and should be called a single time
 // right before the goroutine function or method returns.
 // If the goroutine was not already in a dying state before Done is
 // called, it will be flagged as dying and dead at once with no
-// error.
+// error. It's a runtime error to call Done more than once.
 func (t *Tomb) Done() {
-	t.Kill(nil)
-	close(t.dead)
+	t.init()
+	t.m.Lock()
+	if t.doneCalled {
+		t.m.Unlock()
+		panic("tomb: Done called more than once")
+	}
+	t.doneCalled = true
+	if t.originKind == OriginNone {
+		t.originKind = OriginDone
+		t.originPC = callerPC(1)
+	}
+	hooks := t.kill(nil)
+	t.m.Unlock()
+	if hooks != nil {
+		hooks()
+	}
+	t.m.Lock()
+	t.waitIdle()
+	var closers []*deadCloser
+	if t.x != nil {
+		closers = t.x.deadClosers
+		t.x.deadClosers = nil
+	}
+	t.closingDead = true
+	t.m.Unlock()
+	for i := len(closers) - 1; i >= 0; i-- {
+		c := closers[i].c
+		t.safely(func() {
+			if err := c.Close(); err != nil {
+				t.fold(err)
+			}
+		})
+	}
+	t.m.Lock()
+	deadAt := stampOf(time.Now())
+	var report *DeathReport
+	x := t.x
+	if x != nil && (x.crashDump != nil || x.profileHook != nil) && t.reason != nil {
+		report = t.report(deadAt)
+	}
+	t.m.Unlock()
+	if report != nil && x.crashDump != nil {
+		writeCrashDump(x.crashDump, report)
+	}
+	if report != nil && x.profileHook != nil {
+		p := captureProfile(report, x.profileHeap)
+		t.safely(func() { x.profileHook(p) })
+	}
+	t.m.Lock()
+	t.deadAt = deadAt
+	if t.dead != nil {
+		close(t.dead)
+	}
+	if t.x != nil {
+		for _, ch := range t.x.deathSubs {
+			ch <- t.reason
+			close(ch)
+		}
+		t.x.deathSubs = nil
+	}
+	t.record(EventDead, t.reason)
+	t.m.Unlock()
+}
+
+// Reset restores a dead tomb to the alive state, so that it may be
+// used to track a new goroutine. The tomb is left as it was when first
+// created: a zero value Tomb keeps only its name and ID, while the
+// options provided to New are applied again, so the tomb keeps its
+// name, parent, diagnostics configuration and timers such as the one
+// set by WithIdleKill. Hooks and resources registered by other means,
+// such as CloseOnDying, are not preserved, as they were consumed when
+// the previous goroutine died. Watchers set up for the previous
+// goroutine by Link, Follow, KillOn, KillOnContext or OnSignal are
+// detached, so they never kill the new one; they must be set up again
+// if still needed.
+// It's a runtime error to call Reset if t is not in a dead state.
+// The caller must ensure no one is still using t for the previous
+// goroutine when Reset is called.
+func (t *Tomb) Reset() {
+	t.init()
+	t.m.Lock()
+	if t.deadAt == 0 {
+		t.m.Unlock()
+		panic("tomb: Reset while not dead")
+	}
+	t.dead = nil
+	t.dying = nil
+	t.reason = ErrStillAlive
+	t.dyingAt = 0
+	t.deadAt = 0
+	t.killed = false
+	t.doneCalled = false
+	t.closingDead = false
+	t.originKind = OriginNone
+	t.originPC = 0
+	t.kills = 0
+	t.firstKill = 0
+	t.lastKill = 0
+	t.gen++
+	var opts []Option
+	if x := t.x; x != nil {
+		opts = x.opts
+		t.x = &tombExtra{name: x.name, opts: x.opts}
+	}
+	t.m.Unlock()
+	for _, opt := range opts {
+		opt(t)
+	}
 }
 
 // Kill flags the goroutine as dying for the given reason.
 // Kill may be called multiple times, but only the first
 // non-nil error is recorded as the reason for termination.
 //
-// If reason is ErrDying, the previous reason isn't replaced
-// even if it is nil. It's a runtime error to call Kill with
-// ErrDying if t is not in a dying state.
+// If reason is ErrDying, or the error returned by t.ErrDying,
+// the previous reason isn't replaced even if it is nil. It's a
+// runtime error to call Kill with such an error if t is not in a
+// dying state.
 func (t *Tomb) Kill(reason error) {
+	t.killFrom(reason, OriginKill, 2)
+}
+
+// killFrom works like Kill, recording kind as the origin of the death
+// if t wasn't dying yet. If skip is positive, the caller that many
+// frames above killFrom is also recorded.
+func (t *Tomb) killFrom(reason error, kind OriginKind, skip int) {
+	t.init()
+	t.m.Lock()
+	if skip > 0 {
+		skip++
+	}
+	t.killLocked(reason, kind, skip)
+}
+
+// killIncarnation works like killFrom for watchers set up on behalf of
+// the incarnation gen of t, as returned by incarnation, and does nothing
+// if t was Reset since then.
+func (t *Tomb) killIncarnation(gen uint32, reason error, kind OriginKind) {
+	t.init()
+	t.m.Lock()
+	if t.gen != gen {
+		t.m.Unlock()
+		return
+	}
+	t.killLocked(reason, kind, 0)
+}
+
+// incarnation returns the number of times t was Reset, which identifies
+// the goroutine currently tracked by it.
+func (t *Tomb) incarnation() uint32 {
 	t.init()
 	t.m.Lock()
 	defer t.m.Unlock()
-	if reason == ErrDying {
+	return t.gen
+}
+
+// killLocked implements killFrom. It must be called with t.m held,
+// and releases it.
+func (t *Tomb) killLocked(reason error, kind OriginKind, skip int) {
+	if reason == ErrDying || reason == (dyingError{t}) {
+		defer t.m.Unlock()
 		if t.reason == ErrStillAlive {
 			panic("tomb: Kill with ErrDying while still alive")
 		}
 		return
 	}
+	if kind == OriginLink && t.reason != nil && t.reason != ErrStillAlive {
+		// The error from a linked tomb dying carries no news,
+		// so don't record it as suppressed.
+		t.m.Unlock()
+		return
+	}
+	if kind != OriginKill && t.deadAt != 0 {
+		// Links, triggers and timers firing late must not rewrite
+		// the reason of a tomb that is dead already.
+		t.m.Unlock()
+		return
+	}
+	now := time.Now()
+	if kind == OriginKill {
+		t.killed = true
+		if t.kills == 0 {
+			t.firstKill = stampOf(now)
+		}
+		t.lastKill = stampOf(now)
+		t.kills++
+	}
+	if t.tracing() {
+		var pc uintptr
+		if skip > 0 {
+			pc = callerPC(skip)
+		}
+		t.recordAt(now, EventKill, reason, pc)
+	}
+	if t.originKind == OriginNone {
+		t.originKind = kind
+		if skip > 0 {
+			t.originPC = callerPC(skip)
+		}
+	}
+	hooks := t.kill(reason)
+	t.m.Unlock()
+	if hooks != nil {
+		hooks()
+	}
+}
+
+// kill flags the goroutine as dying for the given reason.
+// It must be called with t.m held. If the tomb just started
+// dying and there are functions registered via onDying, the
+// returned function runs them and must be called after t.m
+// is released.
+func (t *Tomb) kill(reason error) (hooks func()) {
 	if t.reason == nil || t.reason == ErrStillAlive {
 		t.reason = reason
+	} else if reason != nil && reason != t.reason {
+		x := t.extra()
+		x.suppressed = append(x.suppressed, reason)
 	}
-	// If the receive on t.dying succeeds, then
-	// it can only be because we have already closed it.
-	// If it blocks, then we know that it needs to be closed.
-	select {
-	case <-t.dying:
-	default:
-		close(t.dying)
+	if t.dyingAt == 0 {
+		t.dyingAt = stampOf(time.Now())
+		if t.dying != nil {
+			close(t.dying)
+		}
+		t.record(EventDying, t.reason)
+		if t.x == nil {
+			return nil
+		}
+		cause := t.cause()
+		for parent, child := range t.x.child {
+			child.cancel(cause)
+			delete(t.x.child, parent)
+		}
+		if len(t.x.dyingHooks) > 0 {
+			fs := t.x.dyingHooks
+			t.x.dyingHooks = nil
+			reason := t.reason
+			t.track()
+			hooks = func() {
+				defer t.untrack()
+				for i := len(fs) - 1; i >= 0; i-- {
+					f := fs[i].f
+					t.safely(func() { f(reason) })
+				}
+			}
+		}
+	}
+	return hooks
+}
+
+// fold records err as the reason for the goroutine death if no
+// other error was recorded yet, without flagging t as killed.
+// Once t is dead its reason is final, so err is only recorded as
+// suppressed. It must only be called once t is dying.
+func (t *Tomb) fold(err error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.deadAt == 0 {
+		t.kill(err)
+	} else if err != nil && err != t.reason {
+		x := t.extra()
+		x.suppressed = append(x.suppressed, err)
+	}
+}
+
+// track flags some shutdown work as in progress, so that Done waits
+// for it to finish before flagging t as dead. Every call must be
+// followed by a call to untrack. It must be called with t.m held.
+func (t *Tomb) track() {
+	t.extra().busy++
+}
+
+// trackAlive works like track, but only if t is still alive in the
+// incarnation gen, and reports whether it did so.
+func (t *Tomb) trackAlive(gen uint32) bool {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.gen != gen || t.reason != ErrStillAlive {
+		return false
+	}
+	t.track()
+	return true
+}
+
+// untrack flags the work registered via track as finished.
+func (t *Tomb) untrack() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.x
+	x.busy--
+	if x.busy == 0 && x.idle != nil {
+		close(x.idle)
+		x.idle = nil
+	}
+}
+
+// waitIdle blocks until no work registered via track is in progress.
+// It must be called with t.m held, which is released while waiting.
+func (t *Tomb) waitIdle() {
+	for t.x != nil && t.x.busy > 0 {
+		if t.x.idle == nil {
+			t.x.idle = make(chan struct{})
+		}
+		idle := t.x.idle
+		t.m.Unlock()
+		<-idle
+		t.m.Lock()
+	}
+}
+
+// safely calls f, recovering from any panic and recording it as an
+// error via fold, so that one misbehaving shutdown step doesn't
+// prevent the following ones from running. It must only be called
+// once t is dying.
+func (t *Tomb) safely(f func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			t.fold(fmt.Errorf("tomb: panic during shutdown: %v", v))
+		}
+	}()
+	f()
+}
+
+// onDying arranges for f to be called with the reason for the
+// goroutine death once t starts dying. Functions are called in the
+// reverse order of registration. If t is already dying, f is called
+// immediately. Done waits for any such calls still running in other
+// goroutines before flagging t as dead. The returned function cancels
+// the call if it hasn't happened yet.
+func (t *Tomb) onDying(f func(reason error)) (remove func()) {
+	t.init()
+	t.m.Lock()
+	if t.reason == ErrStillAlive {
+		h := &dyingHook{f}
+		x := t.extra()
+		x.dyingHooks = append(x.dyingHooks, h)
+		t.m.Unlock()
+		return func() { t.removeDyingHook(h) }
+	}
+	reason := t.reason
+	dead := t.deadAt != 0
+	if !dead {
+		t.track()
+	}
+	t.m.Unlock()
+	if !dead {
+		defer t.untrack()
+	}
+	t.safely(func() { f(reason) })
+	return func() {}
+}
+
+func (t *Tomb) removeDyingHook(h *dyingHook) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.x == nil {
+		return
+	}
+	for i, other := range t.x.dyingHooks {
+		if other == h {
+			hooks := t.x.dyingHooks
+			copy(hooks[i:], hooks[i+1:])
+			// Clear the vacated slot so the hook can be collected.
+			hooks[len(hooks)-1] = nil
+			t.x.dyingHooks = hooks[:len(hooks)-1]
+			return
+		}
+	}
+}
+
+// cause returns the error used as the cancellation cause of
+// contexts derived from t once it is dying.
+func (t *Tomb) cause() error {
+	if t.reason == nil {
+		return ErrStopped
+	}
+	return t.reason
+}
+
+// WithContext returns a new tomb that is killed when the provided parent
+// context is done, and a copy of parent that is canceled when the tomb
+// starts dying. The returned context carries all values from parent,
+// and is also what Context returns when called with a nil parent.
+// If parent is nil, it defaults to context.Background().
+func WithContext(parent context.Context) (*Tomb, context.Context) {
+	if parent == nil {
+		parent = context.Background()
+	}
+	t := New(WithParentContext(parent))
+	return t, t.Context(nil)
+}
+
+// Context returns a context that is a copy of the provided parent
+// context and is canceled when t starts dying. The cancellation cause
+// of the returned context, as reported by context.Cause, is the reason
+// for the goroutine death, or ErrStopped if no error was provided.
+//
+// If parent is nil, it defaults to the parent provided to WithContext,
+// or to context.Background() if there's none. Calls with the same parent
+// context while t is alive return the same context.
+func (t *Tomb) Context(parent context.Context) context.Context {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	x := t.extra()
+	if parent == nil {
+		parent = x.parent
+		if parent == nil {
+			parent = context.Background()
+		}
+	}
+	if child, ok := x.child[parent]; ok {
+		return child.context
 	}
+	ctx, cancel := context.WithCancelCause(parent)
+	if t.reason != ErrStillAlive {
+		cancel(t.cause())
+		return ctx
+	}
+	if x.child == nil {
+		x.child = make(map[context.Context]childContext)
+	}
+	// Forget children whose parent is done already, so that tombs
+	// deriving a context per request don't grow without bounds.
+	for parent, child := range x.child {
+		if child.context.Err() != nil {
+			child.cancel(nil)
+			delete(x.child, parent)
+		}
+	}
+	x.child[parent] = childContext{ctx, cancel}
+	return ctx
+}
+
+// DetachedContext returns a context that carries the values of the
+// context returned by Context(nil), but is not canceled when t starts
+// dying or when the context provided to WithContext is done. It is meant
+// for final work that must outlive the cancellation it was triggered by,
+// such as flushing logs during shutdown. The returned context has its
+// own deadline after timeout, if positive, and must be released by
+// calling the returned cancel function.
+func (t *Tomb) DetachedContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	t.init()
+	t.m.Lock()
+	var parent context.Context
+	if t.x != nil {
+		parent = t.x.parent
+	}
+	t.m.Unlock()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx := context.WithoutCancel(parent)
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
 }
 
 // Killf works like Kill, but builds the reason providing the received
 // arguments to fmt.Errorf. The generated error is also returned.
 func (t *Tomb) Killf(f string, a ...interface{}) error {
 	err := fmt.Errorf(f, a...)
-	t.Kill(err)
+	t.killFrom(err, OriginKill, 2)
 	return err
 }
 
+// KillCause works like Kill, but builds the reason with the provided
+// message wrapping cause, so that cause may later be retrieved via the
+// Cause method or errors.Is and errors.As. The generated error is also
+// returned.
+func (t *Tomb) KillCause(msg string, cause error) error {
+	var err error
+	if cause == nil {
+		err = errors.New(msg)
+	} else {
+		err = fmt.Errorf("%s: %w", msg, cause)
+	}
+	t.killFrom(err, OriginKill, 2)
+	return err
+}
+
+// Cause returns the innermost error wrapped by the reason for the
+// goroutine death, ErrStopped if the goroutine was stopped with no
+// error, or ErrStillAlive when the goroutine is still alive.
+func (t *Tomb) Cause() error {
+	err := t.Err()
+	if err == nil {
+		return ErrStopped
+	}
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return err
+		}
+		err = inner
+	}
+}
+
 // Err returns the reason for the goroutine death provided via Kill
 // or Killf, or ErrStillAlive when the goroutine is still alive.
 func (t *Tomb) Err() (reason error) {
@@ -174,3 +899,125 @@ func (t *Tomb) Err() (reason error) {
 	t.m.Unlock()
 	return
 }
+
+// ErrOrNil works like Err, but returns nil instead of ErrStillAlive
+// when the goroutine is still alive, following the conventions of
+// context.Context.
+func (t *Tomb) ErrOrNil() error {
+	if err := t.Err(); err != ErrStillAlive {
+		return err
+	}
+	return nil
+}
+
+// State returns whether the goroutine is alive, dying or dead.
+func (t *Tomb) State() State {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.state()
+}
+
+func (t *Tomb) state() State {
+	switch {
+	case t.deadAt != 0:
+		return Dead
+	case t.dyingAt != 0:
+		return Dying
+	}
+	return Alive
+}
+
+// DyingAt returns the time at which the goroutine was flagged as dying,
+// or the zero time if it is still alive.
+func (t *Tomb) DyingAt() time.Time {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.dyingAt.time()
+}
+
+// DeadAt returns the time at which the goroutine was flagged as dead,
+// or the zero time if it is not yet dead.
+func (t *Tomb) DeadAt() time.Time {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.deadAt.time()
+}
+
+// Status holds a consistent view of a Tomb's state at a given moment.
+type Status struct {
+	ID      uint64
+	State   State
+	Reason  error // As returned by Err.
+	Origin  Origin
+	DyingAt time.Time
+	DeadAt  time.Time
+
+	// Kills holds how many times Kill, Killf or KillCause was called
+	// directly, not counting calls with ErrDying nor kills made on t's
+	// behalf by links, triggers or timers, and FirstKill and LastKill
+	// the times of the first and last such calls.
+	Kills     int
+	FirstKill time.Time
+	LastKill  time.Time
+
+	// StepsDone and StepsTotal hold the progress reported via
+	// SetTotal and StepDone.
+	StepsDone  int
+	StepsTotal int
+}
+
+// Snapshot returns the current status of t, captured atomically.
+func (t *Tomb) Snapshot() Status {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	st := Status{
+		ID:      t.id,
+		State:   t.state(),
+		Reason:  t.reason,
+		Origin:  t.deathOrigin(),
+		DyingAt: t.dyingAt.time(),
+		DeadAt:  t.deadAt.time(),
+
+		Kills:     t.kills,
+		FirstKill: t.firstKill.time(),
+		LastKill:  t.lastKill.time(),
+	}
+	if t.x != nil {
+		st.StepsDone = t.x.stepsDone
+		st.StepsTotal = t.x.stepsTotal
+	}
+	return st
+}
+
+// Killed returns whether Kill, Killf or KillCause was called on t.
+// Deaths caused on its behalf by a link, trigger or timer, or by Done
+// alone, don't count, in line with the Kills field of Status.
+func (t *Tomb) Killed() bool {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.killed
+}
+
+// Stopped returns whether the goroutine is dying or dead with no
+// error recorded as the reason, as in a planned shutdown.
+// See Failed for the opposite case.
+func (t *Tomb) Stopped() bool {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.reason == nil
+}
+
+// Failed returns whether an error was recorded as the reason for
+// the goroutine death.
+func (t *Tomb) Failed() bool {
+	t.init()
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.reason != nil && t.reason != ErrStillAlive
+}
@@ -1,10 +1,17 @@
 package tomb_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"gopkg.in/tomb.v1"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 func TestNewTomb(t *testing.T) {
@@ -39,7 +46,7 @@ func TestKillf(t *testing.T) {
 
 	err := tb.Killf("BO%s", "OM")
 	if s := err.Error(); s != "BOOM" {
-		t.Fatalf(`Killf("BO%s", "OM"): want "BOOM", got %q`, s)
+		t.Fatalf(`Killf("BO%%s", "OM"): want "BOOM", got %q`, s)
 	}
 	testState(t, tb, true, false, err)
 
@@ -51,6 +58,595 @@ func TestKillf(t *testing.T) {
 	testState(t, tb, true, true, err)
 }
 
+func TestWaitDying(t *testing.T) {
+	tb := &tomb.Tomb{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tb.WaitDying(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("WaitDying: want deadline exceeded, got %#v", err)
+	}
+
+	err := errors.New("some error")
+	go tb.Kill(err)
+	if reason := tb.WaitDying(context.Background()); reason != err {
+		t.Fatalf("WaitDying: want %#v, got %#v", err, reason)
+	}
+	testState(t, tb, true, false, err)
+}
+
+func TestNotifyDead(t *testing.T) {
+	tb := &tomb.Tomb{}
+	ch := make(chan error)
+	tb.NotifyDead(ch)
+
+	err := errors.New("some error")
+	tb.Kill(err)
+	select {
+	case <-ch:
+		t.Fatalf("NotifyDead: sent before Done")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tb.Done()
+	if reason := <-ch; reason != err {
+		t.Fatalf("NotifyDead: want %#v, got %#v", err, reason)
+	}
+
+	// the reason sent is the one of the goroutine dead, even if the
+	// tomb is reset before the notification goes out
+	tb = &tomb.Tomb{}
+	tb.NotifyDead(ch)
+	tb.Kill(err)
+	tb.Done()
+	tb.Reset()
+	select {
+	case reason := <-ch:
+		if reason != err {
+			t.Fatalf("NotifyDead: want %#v, got %#v", err, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("NotifyDead: lost across Reset")
+	}
+}
+
+func TestReady(t *testing.T) {
+	tb := &tomb.Tomb{}
+	ready := tb.Ready()
+	select {
+	case <-ready:
+		t.Fatalf("<-Ready: should block")
+	default:
+	}
+
+	tb.MarkReady()
+	tb.MarkReady()
+	<-ready
+	<-tb.Ready()
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+}
+
+func TestSubscribeDeath(t *testing.T) {
+	tb := &tomb.Tomb{}
+	subs := []<-chan error{tb.SubscribeDeath(), tb.SubscribeDeath()}
+	err := errors.New("some error")
+	tb.Kill(err)
+	for _, ch := range subs {
+		select {
+		case <-ch:
+			t.Fatalf("SubscribeDeath: delivered before Done")
+		default:
+		}
+	}
+	tb.Done()
+	subs = append(subs, tb.SubscribeDeath())
+	for _, ch := range subs {
+		if reason := <-ch; reason != err {
+			t.Fatalf("SubscribeDeath: want %#v, got %#v", err, reason)
+		}
+		if _, ok := <-ch; ok {
+			t.Fatalf("SubscribeDeath: channel not closed after delivery")
+		}
+	}
+}
+
+func TestStop(t *testing.T) {
+	tb := &tomb.Tomb{}
+	go func() {
+		<-tb.Dying()
+		tb.Done()
+	}()
+	if err := tb.Stop(); err != nil {
+		t.Fatalf("Stop: want nil, got %#v", err)
+	}
+	testState(t, tb, true, true, nil)
+
+	// an earlier reason is preserved and reported
+	err := errors.New("some error")
+	tb = &tomb.Tomb{}
+	tb.Kill(err)
+	tb.Done()
+	if serr := tb.Stop(); serr != err {
+		t.Fatalf("Stop: want %#v, got %#v", err, serr)
+	}
+	testState(t, tb, true, true, err)
+}
+
+func TestClose(t *testing.T) {
+	var c io.Closer = &tomb.Tomb{}
+	tb := c.(*tomb.Tomb)
+	go func() {
+		<-tb.Dying()
+		tb.Done()
+	}()
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: want nil, got %#v", err)
+	}
+	testState(t, tb, true, true, nil)
+}
+
+func TestDoneTwice(t *testing.T) {
+	// Done panics on a second call whether or not the dead
+	// channel was ever allocated.
+	for _, wait := range []bool{false, true} {
+		tb := &tomb.Tomb{}
+		tb.Done()
+		if wait {
+			tb.Wait()
+		}
+		func() {
+			defer func() {
+				if err := recover(); err != "tomb: Done called more than once" {
+					t.Fatalf("Wrong panic on second Done: %v", err)
+				}
+			}()
+			tb.Done()
+		}()
+	}
+}
+
+func TestReset(t *testing.T) {
+	tb := &tomb.Tomb{}
+	err := errors.New("some error")
+	tb.Kill(err)
+	tb.Done()
+	testState(t, tb, true, true, err)
+
+	tb.Reset()
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	tb.Done()
+	testState(t, tb, true, true, nil)
+
+	// Reset being used badly, with a dying tomb.
+	tb = &tomb.Tomb{}
+	tb.Kill(nil)
+	defer func() {
+		err := recover()
+		if err != "tomb: Reset while not dead" {
+			t.Fatalf("Wrong panic on Reset: %v", err)
+		}
+		testState(t, tb, true, false, nil)
+	}()
+	tb.Reset()
+}
+
+func TestResetOptions(t *testing.T) {
+	// options provided to New are applied again
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	defer cancel()
+	var dump bytes.Buffer
+	tb := tomb.New(tomb.WithName("indexer"), tomb.WithParentContext(parent),
+		tomb.WithCrashDump(&dump), tomb.WithIdleKill(20*time.Millisecond, nil))
+	<-tb.Dying()
+	tb.Done()
+	tb.Reset()
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+	if tb.Name() != "indexer" || tb.Context(nil).Value(key{}) != "value" {
+		t.Fatalf("Reset: name or parent context lost")
+	}
+
+	// the idle timer is armed again
+	select {
+	case <-tb.Dying():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Reset: idle timer not rearmed")
+	}
+	tb.Done()
+	if !strings.Contains(dump.String(), "idle timeout") {
+		t.Fatalf("Reset: crash dump not written:\n%s", dump.String())
+	}
+
+	// and the parent context is watched again
+	tb.Reset()
+	cancel()
+	<-tb.Dying()
+	testState(t, tb, true, false, context.Canceled)
+}
+
+func TestResetDetachesWatchers(t *testing.T) {
+	// watchers set up for the previous goroutine never kill the new one,
+	// even if they only notice what they watch after Reset
+	err := errors.New("stale watcher")
+	tombs := make([]*tomb.Tomb, 100)
+	for i := range tombs {
+		tb, ch := &tomb.Tomb{}, make(chan struct{})
+		tb.KillOn(err, ch)
+		tb.Kill(nil)
+		tb.Done()
+		tb.Reset()
+		close(ch)
+		tombs[i] = tb
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, tb := range tombs {
+		testState(t, tb, false, false, tomb.ErrStillAlive)
+	}
+}
+
+func TestState(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if s := tb.State(); s != tomb.Alive {
+		t.Fatalf("State: want %v, got %v", tomb.Alive, s)
+	}
+	if !tb.DyingAt().IsZero() || !tb.DeadAt().IsZero() {
+		t.Fatalf("DyingAt/DeadAt: want zero times while alive")
+	}
+
+	before := time.Now()
+	tb.Kill(nil)
+	if s := tb.State(); s != tomb.Dying {
+		t.Fatalf("State: want %v, got %v", tomb.Dying, s)
+	}
+	dyingAt := tb.DyingAt()
+	if dyingAt.Before(before) || !tb.DeadAt().IsZero() {
+		t.Fatalf("DyingAt/DeadAt: bad times while dying: %v, %v", dyingAt, tb.DeadAt())
+	}
+
+	tb.Kill(errors.New("some error"))
+	if tb.DyingAt() != dyingAt {
+		t.Fatalf("DyingAt: changed by second Kill")
+	}
+
+	tb.Done()
+	if s := tb.State(); s != tomb.Dead {
+		t.Fatalf("State: want %v, got %v", tomb.Dead, s)
+	}
+	if tb.DeadAt().Before(dyingAt) {
+		t.Fatalf("DeadAt: want after %v, got %v", dyingAt, tb.DeadAt())
+	}
+
+	if s := tomb.Dying.String(); s != "dying" {
+		t.Fatalf("Dying.String: want \"dying\", got %q", s)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tb := &tomb.Tomb{}
+	st := tb.Snapshot()
+	if st.State != tomb.Alive || st.Reason != tomb.ErrStillAlive || !st.DyingAt.IsZero() || !st.DeadAt.IsZero() {
+		t.Fatalf("Snapshot: bad status while alive: %#v", st)
+	}
+
+	err := errors.New("some error")
+	tb.Kill(err)
+	tb.Done()
+	st = tb.Snapshot()
+	if st.State != tomb.Dead || st.Reason != err || st.DyingAt != tb.DyingAt() || st.DeadAt != tb.DeadAt() {
+		t.Fatalf("Snapshot: bad status while dead: %#v", st)
+	}
+}
+
+func TestKillCause(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if err := tb.Cause(); err != tomb.ErrStillAlive {
+		t.Fatalf("Cause: want ErrStillAlive, got %#v", err)
+	}
+
+	cause := errors.New("disk full")
+	err := tb.KillCause("cannot flush", cause)
+	if s := err.Error(); s != "cannot flush: disk full" {
+		t.Fatalf(`KillCause: want "cannot flush: disk full", got %q`, s)
+	}
+	testState(t, tb, true, false, err)
+	if c := tb.Cause(); c != cause {
+		t.Fatalf("Cause: want %#v, got %#v", cause, c)
+	}
+
+	// the innermost error is found through further wrapping
+	tb = &tomb.Tomb{}
+	tb.KillCause("outer", fmt.Errorf("inner: %w", cause))
+	if c := tb.Cause(); c != cause {
+		t.Fatalf("Cause: want %#v, got %#v", cause, c)
+	}
+
+	// a nil cause yields an error with the message alone
+	tb = &tomb.Tomb{}
+	err = tb.KillCause("stopped", nil)
+	if s := err.Error(); s != "stopped" || tb.Cause() != err {
+		t.Fatalf("KillCause with nil cause: got %q, Cause %#v", s, tb.Cause())
+	}
+
+	tb = &tomb.Tomb{}
+	tb.Kill(nil)
+	if c := tb.Cause(); c != tomb.ErrStopped {
+		t.Fatalf("Cause: want ErrStopped, got %#v", c)
+	}
+}
+
+func TestContext(t *testing.T) {
+	tb := &tomb.Tomb{}
+	type key struct{}
+	parent := context.WithValue(context.Background(), key{}, "value")
+	ctx := tb.Context(parent)
+	if ctx.Value(key{}) != "value" {
+		t.Fatalf("Context: parent values not propagated")
+	}
+	if tb.Context(parent) != ctx {
+		t.Fatalf("Context: want the same context for the same parent")
+	}
+	select {
+	case <-ctx.Done():
+		t.Fatalf("Context: canceled while tomb is alive")
+	default:
+	}
+
+	err := errors.New("some error")
+	tb.Kill(err)
+	<-ctx.Done()
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("Context: want context.Canceled, got %#v", ctx.Err())
+	}
+	if cause := context.Cause(ctx); cause != err {
+		t.Fatalf("context.Cause: want %#v, got %#v", err, cause)
+	}
+
+	// contexts obtained after death are canceled immediately
+	ctx = tb.Context(nil)
+	<-ctx.Done()
+	if cause := context.Cause(ctx); cause != err {
+		t.Fatalf("context.Cause: want %#v, got %#v", err, cause)
+	}
+
+	// a clean death uses ErrStopped as the cause
+	tb = &tomb.Tomb{}
+	ctx = tb.Context(nil)
+	tb.Kill(nil)
+	<-ctx.Done()
+	if cause := context.Cause(ctx); cause != tomb.ErrStopped {
+		t.Fatalf("context.Cause: want ErrStopped, got %#v", cause)
+	}
+}
+
+func TestContextPrunesDoneParents(t *testing.T) {
+	tb := &tomb.Tomb{}
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := tb.Context(parent)
+	if tb.Context(parent) != ctx {
+		t.Fatalf("Context: want the same context for the same parent")
+	}
+
+	// children of done parents are forgotten when new ones are added
+	cancel()
+	tb.Context(context.Background())
+	if tb.Context(parent) == ctx {
+		t.Fatalf("Context: child of a done parent still retained")
+	}
+}
+
+func TestKilledFailed(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if tb.Killed() || tb.Failed() {
+		t.Fatalf("Killed/Failed: want false, false while alive")
+	}
+	tb.Done()
+	if tb.Killed() || tb.Failed() {
+		t.Fatalf("Killed/Failed: want false, false after Done alone")
+	}
+
+	tb = &tomb.Tomb{}
+	tb.Kill(nil)
+	if !tb.Killed() || tb.Failed() {
+		t.Fatalf("Killed/Failed: want true, false after Kill(nil)")
+	}
+	tb.Kill(tomb.ErrDying)
+	if tb.Failed() {
+		t.Fatalf("Failed: want false after Kill(ErrDying)")
+	}
+	tb.Killf("BOOM")
+	if !tb.Killed() || !tb.Failed() {
+		t.Fatalf("Killed/Failed: want true, true after Killf")
+	}
+	tb.Done()
+	if !tb.Failed() {
+		t.Fatalf("Failed: want true after Done")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancelCause(context.WithValue(context.Background(), key{}, "value"))
+	tb, ctx := tomb.WithContext(parent)
+	if ctx.Value(key{}) != "value" {
+		t.Fatalf("WithContext: parent values not propagated")
+	}
+	if tb.Context(nil) != ctx {
+		t.Fatalf("Context(nil): want the context returned by WithContext")
+	}
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	// the tomb dies when the parent is canceled
+	err := errors.New("some error")
+	cancel(err)
+	<-tb.Dying()
+	testState(t, tb, true, false, err)
+	<-ctx.Done()
+
+	// and the context is canceled when the tomb dies
+	parent, cancel = context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	tb, ctx = tomb.WithContext(parent)
+	tb.Kill(err)
+	<-ctx.Done()
+	if cause := context.Cause(ctx); cause != err {
+		t.Fatalf("context.Cause: want %#v, got %#v", err, cause)
+	}
+	if parent.Err() != nil {
+		t.Fatalf("WithContext: parent canceled by the tomb")
+	}
+}
+
+func TestDetachedContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	tb, _ := tomb.WithContext(parent)
+	cancel()
+	<-tb.Dying()
+
+	ctx, release := tb.DetachedContext(0)
+	if ctx.Value(key{}) != "value" {
+		t.Fatalf("DetachedContext: parent values not propagated")
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("DetachedContext: canceled with the tomb")
+	}
+	release()
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("DetachedContext: not canceled on release")
+	}
+
+	ctx, release = tb.DetachedContext(time.Millisecond)
+	defer release()
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("DetachedContext: want deadline exceeded, got %v", ctx.Err())
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	// A tomb that dies before its channels are requested
+	// allocates nothing but itself.
+	allocs := testing.AllocsPerRun(100, func() {
+		tb := &tomb.Tomb{}
+		tb.Kill(nil)
+		tb.Done()
+		<-tb.Dying()
+		<-tb.Dead()
+	})
+	if allocs > 1 {
+		t.Fatalf("Allocs: want at most 1, got %v", allocs)
+	}
+
+	// Asking for a channel while alive allocates just that channel.
+	allocs = testing.AllocsPerRun(100, func() {
+		tb := &tomb.Tomb{}
+		dying := tb.Dying()
+		tb.Kill(nil)
+		tb.Done()
+		<-dying
+		<-tb.Dead()
+	})
+	if allocs > 2 {
+		t.Fatalf("Allocs: want at most 2, got %v", allocs)
+	}
+
+	// Killing with an error and inspecting the death needs no
+	// optional state either.
+	err := errors.New("some error")
+	allocs = testing.AllocsPerRun(100, func() {
+		tb := &tomb.Tomb{}
+		tb.Kill(err)
+		tb.Kill(err)
+		tb.Done()
+		tb.Wait()
+		tb.State()
+	})
+	if allocs > 1 {
+		t.Fatalf("Allocs: want at most 1, got %v", allocs)
+	}
+}
+
+func TestSize(t *testing.T) {
+	// State used by optional features is kept out of line, so
+	// that plain tombs, often created per request, stay small.
+	if size := unsafe.Sizeof(tomb.Tomb{}); size > 128 {
+		t.Fatalf("Sizeof: want at most 128 bytes, got %d", size)
+	}
+}
+
+func TestErrOrNil(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if err := tb.ErrOrNil(); err != nil {
+		t.Fatalf("ErrOrNil: want nil while alive, got %#v", err)
+	}
+	err := errors.New("some error")
+	tb.Kill(err)
+	if e := tb.ErrOrNil(); e != err {
+		t.Fatalf("ErrOrNil: want %#v, got %#v", err, e)
+	}
+}
+
+func TestStopped(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if tb.Stopped() {
+		t.Fatalf("Stopped: want false while alive")
+	}
+	tb.Kill(nil)
+	if !tb.Stopped() || tb.Failed() {
+		t.Fatalf("Stopped/Failed: want true, false after Kill(nil)")
+	}
+	tb.Kill(errors.New("some error"))
+	if tb.Stopped() || !tb.Failed() {
+		t.Fatalf("Stopped/Failed: want false, true after an error")
+	}
+
+	tb = &tomb.Tomb{}
+	tb.Done()
+	if !tb.Stopped() {
+		t.Fatalf("Stopped: want true after Done")
+	}
+	if err := tb.Wait(); err != nil {
+		t.Fatalf("Wait: want nil, got %#v", err)
+	}
+}
+
+func TestID(t *testing.T) {
+	a, b := &tomb.Tomb{}, &tomb.Tomb{}
+	aid, bid := a.ID(), b.ID()
+	if aid == 0 || bid <= aid {
+		t.Fatalf("ID: want increasing IDs, got %d and %d", aid, bid)
+	}
+	if a.ID() != aid || a.Snapshot().ID != aid {
+		t.Fatalf("ID: changed across calls")
+	}
+}
+
+func TestKillAccounting(t *testing.T) {
+	tb := &tomb.Tomb{}
+	if st := tb.Snapshot(); st.Kills != 0 || !st.FirstKill.IsZero() || !st.LastKill.IsZero() {
+		t.Fatalf("Snapshot: want no kills, got %#v", st)
+	}
+	tb.Kill(nil)
+	first := tb.Snapshot().FirstKill
+	tb.Killf("BOOM")
+	tb.Kill(tomb.ErrDying)
+	tb.Done()
+	st := tb.Snapshot()
+	if st.Kills != 2 || st.FirstKill != first || st.LastKill.Before(first) {
+		t.Fatalf("Snapshot: want 2 kills, got %#v", st)
+	}
+
+	// kills on the tomb's behalf are not counted
+	tb = &tomb.Tomb{}
+	trigger := make(chan struct{})
+	tb.KillOn(nil, trigger)
+	close(trigger)
+	<-tb.Dying()
+	if st := tb.Snapshot(); st.Kills != 0 || tb.Killed() {
+		t.Fatalf("Snapshot: want no kill calls, got %#v", st)
+	}
+}
+
 func TestErrDying(t *testing.T) {
 	// ErrDying being used properly, after a clean death.
 	tb := &tomb.Tomb{}
@@ -76,6 +672,33 @@ func TestErrDying(t *testing.T) {
 	tb.Kill(tomb.ErrDying)
 }
 
+func TestTombErrDying(t *testing.T) {
+	outer, inner := &tomb.Tomb{}, &tomb.Tomb{}
+	if !errors.Is(outer.ErrDying(), tomb.ErrDying) {
+		t.Fatalf("ErrDying: must match tomb.ErrDying")
+	}
+	if outer.ErrDying() == inner.ErrDying() {
+		t.Fatalf("ErrDying: tombs must have distinct errors")
+	}
+
+	// a tomb's own dying error keeps the previous reason
+	inner.Kill(nil)
+	inner.Kill(inner.ErrDying())
+	testState(t, inner, true, false, nil)
+
+	// another tomb's dying error is a real reason
+	inner.Kill(outer.ErrDying())
+	testState(t, inner, true, false, outer.ErrDying())
+
+	defer func() {
+		err := recover()
+		if err != "tomb: Kill with ErrDying while still alive" {
+			t.Fatalf("Wrong panic on Kill(t.ErrDying()): %v", err)
+		}
+	}()
+	outer.Kill(outer.ErrDying())
+}
+
 func testState(t *testing.T, tb *tomb.Tomb, wantDying, wantDead bool, wantErr error) {
 	select {
 	case <-tb.Dying():
@@ -0,0 +1,148 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// The tombtest package provides helpers for testing code that runs
+// under a tomb.
+package tombtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/tomb.v1"
+	"testing"
+	"time"
+)
+
+// DefaultBound is how long CheckWorker waits for a killed worker
+// to return.
+const DefaultBound = time.Second
+
+// killDelays holds when CheckWorker kills the worker on each run,
+// relative to its start. A negative delay kills the tomb before the
+// worker is started.
+var killDelays = []time.Duration{-1, 0, time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond}
+
+// CheckWorker runs f under a new tomb several times, killing the
+// tomb after different delays, including before f is even started.
+// It fails tb if f returns before the tomb is killed, doesn't return
+// within DefaultBound after the tomb is killed, or returns an error
+// other than nil, ErrDying, ErrStopped or context.Canceled (as
+// returned by a context obtained via the tomb's Context method).
+//
+// CheckWorker calls Done on the tomb once f returns, so f must not call
+// it itself, as done with a deferred t.Done() in the function started
+// as the tracked goroutine; a worker that does is reported as a failure.
+// A worker that fails to return is left running.
+func CheckWorker(tb testing.TB, f func(t *tomb.Tomb) error) {
+	tb.Helper()
+	CheckWorkerWithin(tb, f, DefaultBound)
+}
+
+// CheckWorkerWithin works like CheckWorker, but waits for up to bound
+// for the worker to return once killed.
+func CheckWorkerWithin(tb testing.TB, f func(t *tomb.Tomb) error, bound time.Duration) {
+	tb.Helper()
+	for _, delay := range killDelays {
+		checkWorker(tb, f, delay, bound)
+	}
+}
+
+func checkWorker(tb testing.TB, f func(t *tomb.Tomb) error, delay, bound time.Duration) {
+	tb.Helper()
+	var when string
+	switch {
+	case delay < 0:
+		when = "before starting"
+	case delay == 0:
+		when = "right after starting"
+	default:
+		when = fmt.Sprintf("%v after starting", delay)
+	}
+
+	t := &tomb.Tomb{}
+	if delay < 0 {
+		t.Kill(nil)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- f(t)
+	}()
+	// finish calls Done for the returned worker, unless it did so itself.
+	finish := func() {
+		if t.State() == tomb.Dead {
+			tb.Errorf("tombtest: worker called Done itself, which CheckWorker does once it returns")
+			return
+		}
+		t.Done()
+	}
+	early := func(err error) {
+		finish()
+		tb.Errorf("tombtest: worker returned before being killed %s: %v", when, err)
+	}
+	if delay > 0 {
+		select {
+		case err := <-done:
+			early(err)
+			return
+		case <-time.After(delay):
+		}
+	} else if delay == 0 {
+		select {
+		case err := <-done:
+			early(err)
+			return
+		default:
+		}
+	}
+	t.Kill(nil)
+	select {
+	case err := <-done:
+		finish()
+		if !cleanExit(err) {
+			tb.Errorf("tombtest: worker killed %s returned %v, want nil, ErrDying, ErrStopped or context.Canceled", when, err)
+		}
+	case <-time.After(bound):
+		tb.Errorf("tombtest: worker killed %s didn't return within %v", when, bound)
+	}
+}
+
+// cleanExit returns whether err is an acceptable result for a worker
+// that was asked to stop.
+func cleanExit(err error) bool {
+	return err == nil || errors.Is(err, tomb.ErrDying) || errors.Is(err, tomb.ErrStopped) || errors.Is(err, context.Canceled)
+}
+
+// RequireClean waits for t to die, and fails tb with the death report
+// if it died with an error. It is the testing variant of t.MustWait.
+func RequireClean(tb testing.TB, t *tomb.Tomb) {
+	tb.Helper()
+	if t.Wait() != nil {
+		tb.Fatalf("tombtest: %v", t.Report())
+	}
+}
@@ -0,0 +1,94 @@
+package tombtest_test
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/tomb.v1"
+	"gopkg.in/tomb.v1/tombtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder captures failures reported by the helpers under test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestCheckWorker(t *testing.T) {
+	tombtest.CheckWorker(t, func(tb *tomb.Tomb) error {
+		<-tb.Dying()
+		return tomb.ErrDying
+	})
+	tombtest.CheckWorker(t, func(tb *tomb.Tomb) error {
+		ctx := tb.Context(nil)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+}
+
+func TestCheckWorkerFailures(t *testing.T) {
+	tests := []struct {
+		worker func(tb *tomb.Tomb) error
+		want   string
+	}{{
+		func(tb *tomb.Tomb) error {
+			return nil
+		},
+		"returned before being killed",
+	}, {
+		func(tb *tomb.Tomb) error {
+			<-tb.Dying()
+			return errors.New("some error")
+		},
+		"returned some error",
+	}, {
+		func(tb *tomb.Tomb) error {
+			<-tb.Dying()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+		"didn't return within 10ms",
+	}, {
+		func(tb *tomb.Tomb) error {
+			defer tb.Done()
+			<-tb.Dying()
+			return nil
+		},
+		"called Done itself",
+	}}
+	for _, test := range tests {
+		r := &recorder{TB: t}
+		tombtest.CheckWorkerWithin(r, test.worker, 10*time.Millisecond)
+		if len(r.errors) == 0 || !strings.Contains(r.errors[0], test.want) {
+			t.Fatalf("CheckWorker: want failure %q, got %q", test.want, r.errors)
+		}
+	}
+}
+
+func TestRequireClean(t *testing.T) {
+	tb := &tomb.Tomb{}
+	tb.Done()
+	tombtest.RequireClean(t, tb)
+
+	tb = &tomb.Tomb{}
+	tb.Kill(errors.New("some error"))
+	tb.Done()
+	r := &recorder{TB: t}
+	tombtest.RequireClean(r, tb)
+	want := fmt.Sprintf("tombtest: tomb %d died: some error", tb.ID())
+	if len(r.errors) != 1 || !strings.HasPrefix(r.errors[0], want) {
+		t.Fatalf("RequireClean: want failure %q, got %q", want, r.errors)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+)
+
+// KillOn arranges for t to be killed with the given reason as soon as
+// any of the provided channels is closed or receives a value. A single
+// goroutine watches all the channels, and it terminates once t starts
+// dying for any reason.
+func (t *Tomb) KillOn(reason error, chans ...<-chan struct{}) {
+	cases := make([]reflect.Value, len(chans))
+	reasons := make([]func() error, len(chans))
+	for i, ch := range chans {
+		cases[i] = reflect.ValueOf(ch)
+		reasons[i] = func() error { return reason }
+	}
+	t.killOn(cases, reasons)
+}
+
+// KillOnContext arranges for t to be killed as soon as any of the
+// provided contexts is done, with the context cancellation cause as
+// reported by context.Cause as the reason. A single goroutine watches
+// all the contexts, and it terminates once t starts dying for any
+// reason.
+func (t *Tomb) KillOnContext(ctxs ...context.Context) {
+	cases := make([]reflect.Value, len(ctxs))
+	reasons := make([]func() error, len(ctxs))
+	for i, ctx := range ctxs {
+		cases[i] = reflect.ValueOf(ctx.Done())
+		reasons[i] = func() error { return context.Cause(ctx) }
+	}
+	t.killOn(cases, reasons)
+}
+
+// OnSignal arranges for handler to be called every time the process
+// receives sig, until t starts dying, as done for reloading the
+// configuration on SIGHUP. Calls to handler are serialized on a single
+// goroutine, and signals received while handler runs are coalesced into
+// a single further call. If handler returns an error, t is killed with it.
+//
+// Handler calls are tracked by t: none is started once t is dying, and
+// Done waits for a call in progress to return before flagging t as dead.
+func (t *Tomb) OnSignal(sig os.Signal, handler func() error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, sig)
+	gen, dying := t.incarnation(), t.Dying()
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-sigs:
+				if !t.trackAlive(gen) {
+					return
+				}
+				err := handler()
+				if err != nil {
+					t.killIncarnation(gen, err, OriginTrigger)
+				}
+				t.untrack()
+				if err != nil {
+					return
+				}
+			case <-dying:
+				return
+			}
+		}
+	}()
+}
+
+func (t *Tomb) killOn(chans []reflect.Value, reasons []func() error) {
+	if len(chans) == 0 {
+		return
+	}
+	gen := t.incarnation()
+	cases := make([]reflect.SelectCase, len(chans)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(t.Dying())}
+	for i, ch := range chans {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: ch}
+	}
+	go func() {
+		chosen, _, _ := reflect.Select(cases)
+		if chosen > 0 {
+			t.killIncarnation(gen, reasons[chosen-1](), OriginTrigger)
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package tomb_test
+
+import (
+	"context"
+	"errors"
+	"gopkg.in/tomb.v1"
+	"testing"
+)
+
+func TestKillOn(t *testing.T) {
+	tb := &tomb.Tomb{}
+	a, b := make(chan struct{}), make(chan struct{})
+	err := errors.New("stop requested")
+	tb.KillOn(err, a, b)
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	close(b)
+	<-tb.Dying()
+	testState(t, tb, true, false, err)
+}
+
+func TestKillOnContext(t *testing.T) {
+	tb := &tomb.Tomb{}
+	ctx1, cancel1 := context.WithCancelCause(context.Background())
+	defer cancel1(nil)
+	ctx2, cancel2 := context.WithCancelCause(context.Background())
+	tb.KillOnContext(ctx1, ctx2)
+	testState(t, tb, false, false, tomb.ErrStillAlive)
+
+	err := errors.New("upstream gone")
+	cancel2(err)
+	<-tb.Dying()
+	testState(t, tb, true, false, err)
+}
@@ -0,0 +1,76 @@
+//go:build unix
+
+package tomb_test
+
+import (
+	"errors"
+	"gopkg.in/tomb.v1"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOnSignal(t *testing.T) {
+	tb := &tomb.Tomb{}
+	err := errors.New("bad config")
+	calls := make(chan int)
+	n := 0
+	tb.OnSignal(syscall.SIGHUP, func() error {
+		n++
+		calls <- n
+		if n == 2 {
+			return err
+		}
+		return nil
+	})
+	for i := 1; i <= 2; i++ {
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+		select {
+		case got := <-calls:
+			if got != i {
+				t.Fatalf("OnSignal: want call %d, got %d", i, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("OnSignal: handler not called")
+		}
+		if i == 1 {
+			testState(t, tb, false, false, tomb.ErrStillAlive)
+		}
+	}
+	<-tb.Dying()
+	testState(t, tb, true, false, err)
+	if o := tb.DeathOrigin(); o.Kind != tomb.OriginTrigger {
+		t.Fatalf("DeathOrigin: want trigger, got %v", o)
+	}
+}
+
+func TestOnSignalTracked(t *testing.T) {
+	// Done waits for a handler call in progress
+	tb := &tomb.Tomb{}
+	started, release := make(chan struct{}), make(chan struct{})
+	var finished bool
+	tb.OnSignal(syscall.SIGHUP, func() error {
+		close(started)
+		<-release
+		finished = true
+		return nil
+	})
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	<-started
+	tb.Kill(nil)
+	done := make(chan struct{})
+	go func() {
+		tb.Done()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("Done: returned while a handler was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	if !finished {
+		t.Fatalf("Done: returned before the handler finished")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+//
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//     * Redistributions of source code must retain the above copyright notice,
+//       this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above copyright notice,
+//       this list of conditions and the following disclaimer in the documentation
+//       and/or other materials provided with the distribution.
+//     * Neither the name of the copyright holder nor the names of its
+//       contributors may be used to endorse or promote products derived from
+//       this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT OWNER OR
+// CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL,
+// EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO,
+// PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR
+// PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+// LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING
+// NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package tomb
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchdogExitCode is the exit code used by the default watchdog
+// escalation of WithWatchdog.
+const WatchdogExitCode = 70
+
+// WithWatchdog makes the tomb run escalate if it is not dead within
+// the provided duration after it starts dying. If escalate is nil,
+// the stack traces of all goroutines are written to stderr and the
+// process exits with WatchdogExitCode, on the basis that a clean
+// restart is better than a process stuck in shutdown.
+func WithWatchdog(d time.Duration, escalate func(t *Tomb)) Option {
+	if escalate == nil {
+		escalate = exitStuck
+	}
+	return func(t *Tomb) {
+		t.onDying(func(error) {
+			// Grab the dead channel now, as t may be Reset
+			// and dying again by the time the goroutine runs.
+			dead := t.Dead()
+			go func() {
+				timer := time.NewTimer(d)
+				defer timer.Stop()
+				select {
+				case <-dead:
+				case <-timer.C:
+					escalate(t)
+				}
+			}()
+		})
+	}
+}
+
+func exitStuck(t *Tomb) {
+	st := t.Snapshot()
+	fmt.Fprintf(os.Stderr, "tomb %d %q still dying after %v: %v\n\n%s\n",
+		st.ID, t.Name(), time.Since(st.DyingAt), st.Reason, allStacks())
+	os.Exit(WatchdogExitCode)
+}
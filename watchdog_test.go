@@ -0,0 +1,46 @@
+package tomb_test
+
+import (
+	"gopkg.in/tomb.v1"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	stuck := make(chan *tomb.Tomb, 1)
+	escalate := func(t *tomb.Tomb) { stuck <- t }
+
+	tb := tomb.New(tomb.WithWatchdog(10*time.Millisecond, escalate))
+	tb.Kill(nil)
+	select {
+	case st := <-stuck:
+		if st != tb {
+			t.Fatalf("watchdog escalated for the wrong tomb")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watchdog did not escalate")
+	}
+	tb.Done()
+
+	// a tomb dying in time is left alone
+	tb = tomb.New(tomb.WithWatchdog(50*time.Millisecond, escalate))
+	tb.Kill(nil)
+	tb.Done()
+	select {
+	case <-stuck:
+		t.Fatalf("watchdog escalated for a dead tomb")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// and so is one reset right after dying, as the new goroutine
+	// isn't dying yet
+	tb = tomb.New(tomb.WithWatchdog(20*time.Millisecond, escalate))
+	tb.Kill(nil)
+	tb.Done()
+	tb.Reset()
+	select {
+	case <-stuck:
+		t.Fatalf("watchdog escalated for a reset tomb")
+	case <-time.After(100 * time.Millisecond):
+	}
+}